@@ -0,0 +1,76 @@
+package usl
+
+import "sync"
+
+// Builder incrementally builds a model as measurements are added to it, refitting the model from
+// the previous model's parameters each time. The zero value is ready to use, and a Builder is safe
+// for concurrent use: readers are never blocked by a refit in progress.
+type Builder struct {
+	addMu sync.Mutex // Serializes calls to Add.
+
+	mu           sync.RWMutex // Guards the measurements and the current model.
+	measurements []Measurement
+	model        *Model
+}
+
+// Add adds a measurement and refits the model. Returns the new model, or
+// ErrInsufficientMeasurements if too few measurements have been added to build one.
+func (b *Builder) Add(m Measurement) (*Model, error) {
+	b.addMu.Lock()
+	defer b.addMu.Unlock()
+
+	// Measurements are only appended, so the slice can be refit after the lock is released.
+	b.mu.Lock()
+	b.measurements = append(b.measurements, m)
+	measurements := b.measurements
+	b.mu.Unlock()
+
+	if len(measurements) < minMeasurements {
+		return nil, ErrInsufficientMeasurements
+	}
+
+	// Warm-start from the previous model's parameters, if any.
+	var (
+		next *Model
+		err  error
+	)
+
+	if prev := b.Model(); prev != nil {
+		next, err = buildFrom(measurements, prev, warmTau)
+	} else {
+		next, err = Build(measurements)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.model = next
+	b.mu.Unlock()
+
+	return b.Model(), nil
+}
+
+// Model returns a copy of the current model, or nil if too few measurements have been added to
+// build one.
+func (b *Builder) Model() *Model {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.model == nil {
+		return nil
+	}
+
+	m := *b.model
+
+	return &m
+}
+
+// Measurements returns a copy of the measurements added so far.
+func (b *Builder) Measurements() []Measurement {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return append([]Measurement(nil), b.measurements...)
+}
@@ -0,0 +1,105 @@
+package usl
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	var b Builder
+
+	for i, m := range measurements {
+		model, err := b.Add(m)
+		if i < minMeasurements-1 {
+			if !errors.Is(err, ErrInsufficientMeasurements) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if b.Model() != nil {
+				t.Fatal("unexpected model")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "Model", model, b.Model())
+	}
+
+	assert.Equal(t, "Model", build(t), b.Model(), epsilon)
+	assert.Equal(t, "Measurements", measurements, b.Measurements())
+}
+
+func TestBuilder_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	var (
+		b  Builder
+		wg sync.WaitGroup
+	)
+
+	for _, m := range measurements {
+		wg.Add(2)
+
+		go func(m Measurement) {
+			defer wg.Done()
+
+			_, _ = b.Add(m)
+		}(m)
+
+		go func() {
+			defer wg.Done()
+
+			_ = b.Model()
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, "Model", build(t), b.Model(), epsilon)
+}
+
+func TestBuilder_ReadDuringRefit(t *testing.T) {
+	t.Parallel()
+
+	// Enough measurements that refitting them takes a while.
+	var b Builder
+	for i := 0; i < 100000; i++ {
+		b.measurements = append(b.measurements, measurements[i%len(measurements)])
+	}
+
+	n := len(b.measurements)
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := b.Add(measurements[0])
+		done <- err
+	}()
+
+	// Once Add has added the measurement, it's refitting. A reader which waited for the refit to
+	// finish would see its model.
+	for len(b.Measurements()) == n {
+		runtime.Gosched()
+	}
+
+	if m := b.Model(); m != nil {
+		t.Errorf("Model() = %v during the refit, want nil", m)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Model() == nil {
+		t.Error("Model() = nil after the refit")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codahale/usl"
+)
+
+// parseGoBench parses the output of go test -bench, returning a measurement of each result of the
+// given benchmark. The concurrency of each result is its GOMAXPROCS level, and its throughput is
+// derived from its ns/op. If no benchmark is given, the output must contain only one.
+func parseGoBench(filename, benchmark string) ([]usl.Measurement, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	results := map[string][]usl.Measurement{}
+
+	s := bufio.NewScanner(f)
+	for i := 1; s.Scan(); i++ {
+		match := benchmarkLine.FindStringSubmatch(s.Text())
+		if match == nil {
+			continue
+		}
+
+		procs := uint64(1)
+		if match[2] != "" {
+			procs, err = strconv.ParseUint(match[2], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("error at line %d: %w", i, err)
+			}
+		}
+
+		nsPerOp, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error at line %d: %w", i, err)
+		}
+
+		results[match[1]] = append(results[match[1]], usl.ConcurrencyAndThroughput(procs, 1e9/nsPerOp))
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if benchmark != "" {
+		if measurements, ok := results[benchmark]; ok {
+			return measurements, nil
+		}
+
+		return nil, fmt.Errorf("%w for %s", errNoBenchmarkResults, benchmark)
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	switch len(names) {
+	case 0:
+		return nil, errNoBenchmarkResults
+	case 1:
+		return results[names[0]], nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errAmbiguousBenchmark, strings.Join(names, ", "))
+	}
+}
+
+// benchmarkLine matches a benchmark result, capturing its name, its GOMAXPROCS suffix (which is
+// omitted when GOMAXPROCS is 1), and its ns/op.
+//
+//nolint:gochecknoglobals // effectively constant
+var benchmarkLine = regexp.MustCompile(`^(Benchmark\S*?)(?:-(\d+))?\s+\d+\s+([0-9.eE+-]+) ns/op`)
+
+var (
+	errNoBenchmarkResults = errors.New("no benchmark results")
+	errAmbiguousBenchmark = errors.New("choose one benchmark with --benchmark")
+)
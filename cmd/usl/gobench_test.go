@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseGoBench(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseGoBench("bench.txt", "BenchmarkCache")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []usl.Measurement{
+		usl.ConcurrencyAndThroughput(1, 1e8),
+		usl.ConcurrencyAndThroughput(2, 1e9/5.3),
+		usl.ConcurrencyAndThroughput(4, 1e9/2.9),
+		usl.ConcurrencyAndThroughput(8, 1e9/1.7),
+		usl.ConcurrencyAndThroughput(16, 1e9/1.1),
+		usl.ConcurrencyAndThroughput(32, 1e9/0.9),
+		usl.ConcurrencyAndThroughput(64, 1e9/0.95),
+	}
+
+	assert.Equal(t, "measurements", want, got, cmpopts.EquateApprox(1e-9, 0))
+}
+
+func TestParseGoBench_SubBenchmark(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseGoBench("bench.txt", "BenchmarkOther/size=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "measurements", []usl.Measurement{usl.ConcurrencyAndThroughput(8, 1e6)}, got)
+}
+
+func TestParseGoBench_Ambiguous(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseGoBench("bench.txt", ""); !errors.Is(err, errAmbiguousBenchmark) {
+		t.Errorf("err = %v, want %v", err, errAmbiguousBenchmark)
+	}
+}
+
+func TestParseGoBench_Missing(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseGoBench("bench.txt", "BenchmarkNope"); !errors.Is(err, errNoBenchmarkResults) {
+		t.Errorf("err = %v, want %v", err, errNoBenchmarkResults)
+	}
+
+	if _, err := parseGoBench("example.csv", ""); !errors.Is(err, errNoBenchmarkResults) {
+		t.Errorf("err = %v, want %v", err, errNoBenchmarkResults)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codahale/usl"
+)
+
+// parseHey parses the output of one or more runs of hey, returning a measurement of each. hey
+// doesn't report its concurrency, so it is derived from each run's mean latency and its request
+// rate, excluding requests which failed or received non-2xx or 3xx responses.
+func parseHey(r io.Reader) ([]usl.Measurement, error) {
+	var (
+		measurements []usl.Measurement
+		run          *heyRun
+		section      string
+	)
+
+	s := bufio.NewScanner(r)
+	for i := 1; s.Scan(); i++ {
+		line := strings.TrimSpace(s.Text())
+
+		var err error
+
+		switch {
+		case line == "Summary:":
+			if run != nil {
+				measurements = append(measurements, run.measurement())
+			}
+
+			run = &heyRun{}
+			section = line
+		case strings.HasSuffix(line, ":"):
+			section = line
+		case run == nil:
+			continue
+		case strings.HasPrefix(line, "Average:"):
+			run.latency, err = parseSeconds(strings.TrimPrefix(line, "Average:"))
+		case strings.HasPrefix(line, "Requests/sec:"):
+			run.x, err = strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(line, "Requests/sec:")), 64)
+		case section == "Status code distribution:" && heyStatus.MatchString(line):
+			match := heyStatus.FindStringSubmatch(line)
+
+			var n float64
+
+			n, err = strconv.ParseFloat(match[2], 64)
+			if match[1][0] == '2' || match[1][0] == '3' {
+				run.successes += n
+			} else {
+				run.failures += n
+			}
+		case section == "Error distribution:" && heyError.MatchString(line):
+			var n float64
+
+			n, err = strconv.ParseFloat(heyError.FindStringSubmatch(line)[1], 64)
+			run.failures += n
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error at line %d: %w", i, err)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if run != nil {
+		measurements = append(measurements, run.measurement())
+	}
+
+	return measurements, nil
+}
+
+// heyRun is the summary of a run of hey.
+type heyRun struct {
+	latency, x, successes, failures float64
+}
+
+// measurement returns a measurement of the run.
+func (run *heyRun) measurement() usl.Measurement {
+	x := run.x
+	if total := run.successes + run.failures; total > 0 {
+		x *= run.successes / total
+	}
+
+	return usl.ThroughputAndLatency(x, time.Duration(run.latency*float64(time.Second)))
+}
+
+// parseSeconds parses a duration like "0.0123 secs".
+func parseSeconds(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), " secs"), 64)
+}
+
+//nolint:gochecknoglobals // effectively constant
+var (
+	// heyStatus matches a line of hey's status code distribution, capturing the status code and the
+	// number of responses with it.
+	heyStatus = regexp.MustCompile(`^\[(\d+)]\s+(\d+) responses$`)
+
+	// heyError matches a line of hey's error distribution, capturing the number of errors.
+	heyError = regexp.MustCompile(`^\[(\d+)]\s`)
+)
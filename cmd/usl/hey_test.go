@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const heyOutput = `
+Summary:
+  Total:	2.0000 secs
+  Slowest:	0.2046 secs
+  Fastest:	0.0004 secs
+  Average:	0.0100 secs
+  Requests/sec:	1000.0000
+
+  Total data:	1000 bytes
+  Size/request:	1 bytes
+
+Response time histogram:
+  0.000 [1]	|
+  0.021 [1999]	|■■■■■■■■■■■■■■■■■■■■
+
+Latency distribution:
+  10% in 0.0050 secs
+  99% in 0.0500 secs
+
+Details (average, fastest, slowest):
+  DNS+dialup:	0.0001 secs, 0.0004 secs, 0.2046 secs
+  req write:	0.0000 secs, 0.0000 secs, 0.0020 secs
+
+Status code distribution:
+  [200]	2000 responses
+
+Summary:
+  Total:	2.0000 secs
+  Slowest:	0.2046 secs
+  Fastest:	0.0004 secs
+  Average:	0.0200 secs
+  Requests/sec:	1000.0000
+
+Status code distribution:
+  [200]	1500 responses
+  [503]	300 responses
+
+Error distribution:
+  [200]	Get "http://localhost:8080/": dial tcp 127.0.0.1:8080: connect: connection refused
+`
+
+func TestParseHey(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseHey(strings.NewReader(heyOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "measurements", []usl.Measurement{
+		usl.ThroughputAndLatency(1000, 10*time.Millisecond),
+		usl.ThroughputAndLatency(750, 20*time.Millisecond),
+	}, got, cmpopts.EquateApprox(1e-9, 0))
+}
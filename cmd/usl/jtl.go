@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/codahale/usl"
+)
+
+// parseJTL parses a JMeter results file in CSV format, returning a measurement of the samples taken
+// at each number of active threads. Each measurement's throughput is the number of successful
+// samples divided by the time from the first one's start to the last one's end, and its latency is
+// their mean elapsed time.
+//
+// The file must have a header, and timestamps must be in milliseconds (JMeter's default).
+func parseJTL(r io.Reader) ([]usl.Measurement, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	for _, name := range []string{"timeStamp", "elapsed", "success", "allThreads"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", errInvalidInput, name)
+		}
+	}
+
+	groups := map[float64]*jtlGroup{}
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		start, err := strconv.ParseFloat(record[columns["timeStamp"]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error at line %d: %w", line, err)
+		}
+
+		elapsed, err := strconv.ParseFloat(record[columns["elapsed"]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error at line %d: %w", line, err)
+		}
+
+		threads, err := strconv.ParseFloat(record[columns["allThreads"]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error at line %d: %w", line, err)
+		}
+
+		g, ok := groups[threads]
+		if !ok {
+			g = &jtlGroup{start: math.Inf(1), end: math.Inf(-1)}
+			groups[threads] = g
+		}
+
+		g.start = math.Min(g.start, start)
+		g.end = math.Max(g.end, start+elapsed)
+
+		if record[columns["success"]] == "true" {
+			g.successes++
+			g.elapsed += elapsed
+		}
+	}
+
+	measurements := make([]usl.Measurement, 0, len(groups))
+
+	for threads, g := range groups {
+		if g.successes == 0 || g.end <= g.start {
+			continue
+		}
+
+		measurements = append(measurements, usl.Measurement{
+			Concurrency: threads,
+			Throughput:  g.successes / ((g.end - g.start) / 1000),
+			Latency:     g.elapsed / g.successes / 1000,
+		})
+	}
+
+	sort.Slice(measurements, func(i, j int) bool {
+		return measurements[i].Concurrency < measurements[j].Concurrency
+	})
+
+	return measurements, nil
+}
+
+// jtlGroup is a summary of the samples taken at a number of active threads, with times in
+// milliseconds.
+type jtlGroup struct {
+	start, end, successes, elapsed float64
+}
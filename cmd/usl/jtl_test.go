@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+//nolint:lll // JMeter writes long lines
+const jtlOutput = `timeStamp,elapsed,label,responseCode,responseMessage,threadName,dataType,success,failureMessage,bytes,sentBytes,grpThreads,allThreads,URL,Latency,IdleTime,Connect
+1600000002000,100,home,200,OK,Group 1-1,text,true,,100,10,2,2,http://localhost/,90,0,1
+1600000002000,300,home,200,OK,Group 1-2,text,true,,100,10,2,2,http://localhost/,90,0,1
+1600000002100,100,home,500,Error,Group 1-1,text,false,,100,10,2,2,http://localhost/,90,0,1
+1600000000000,100,home,200,OK,Group 1-1,text,true,,100,10,1,1,http://localhost/,90,0,1
+1600000000100,100,home,200,OK,Group 1-1,text,true,,100,10,1,1,http://localhost/,90,0,1
+`
+
+func TestParseJTL(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseJTL(strings.NewReader(jtlOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "measurements", []usl.Measurement{
+		{Concurrency: 1, Throughput: 10, Latency: 0.1},
+		{Concurrency: 2, Throughput: 2 / 0.3, Latency: 0.2},
+	}, got, cmpopts.EquateApprox(1e-9, 0))
+}
+
+func TestParseJTL_MissingColumns(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseJTL(strings.NewReader("timeStamp,elapsed\n")); !errors.Is(err, errInvalidInput) {
+		t.Errorf("err = %v, want %v", err, errInvalidInput)
+	}
+}
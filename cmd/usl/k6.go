@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/codahale/usl"
+)
+
+// parseK6 parses one or more k6 JSON summaries, as written by --summary-export or by
+// handleSummary, returning a measurement of each. The concurrency of each summary is derived from
+// its mean HTTP request duration and its rate of HTTP requests, excluding failed requests.
+func parseK6(r io.Reader) ([]usl.Measurement, error) {
+	var measurements []usl.Measurement
+
+	d := json.NewDecoder(r)
+
+	for {
+		var summary struct {
+			Metrics map[string]k6Metric `json:"metrics"`
+		}
+
+		if err := d.Decode(&summary); errors.Is(err, io.EOF) {
+			return measurements, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		reqs, duration := summary.Metrics["http_reqs"], summary.Metrics["http_req_duration"]
+		if reqs.value("rate") == 0 || duration.value("avg") == 0 {
+			return nil, fmt.Errorf("%w: missing http_reqs or http_req_duration metrics", errInvalidInput)
+		}
+
+		// The failure rate is "value" in exported summaries and "rate" in handleSummary's.
+		failed := summary.Metrics["http_req_failed"]
+		x := reqs.value("rate") * (1 - failed.value("value") - failed.value("rate"))
+
+		measurements = append(measurements, usl.ThroughputAndLatency(x, k6Duration(duration.value("avg"))))
+	}
+}
+
+// k6Metric is a metric in a k6 summary. Exported summaries have the metric's values as fields of
+// the metric; handleSummary's have them in a separate object.
+type k6Metric map[string]interface{}
+
+// value returns the named value of the metric, or zero if it has none.
+func (m k6Metric) value(name string) float64 {
+	if values, ok := m["values"].(map[string]interface{}); ok {
+		return k6Metric(values).value(name)
+	}
+
+	v, _ := m[name].(float64)
+
+	return v
+}
+
+// k6Duration returns a duration in milliseconds, as k6 records them.
+func k6Duration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
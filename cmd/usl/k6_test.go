@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// The first summary is from --summary-export, and the second from handleSummary.
+const k6Output = `{
+  "metrics": {
+    "http_req_duration": {"avg": 10, "min": 1, "med": 8, "max": 90, "p(90)": 15, "p(95)": 20},
+    "http_req_failed": {"passes": 0, "fails": 1000, "thresholds": {"rate<0.01": false}, "value": 0},
+    "http_reqs": {"count": 1000, "rate": 100},
+    "vus": {"value": 1, "min": 1, "max": 1}
+  }
+}
+{
+  "metrics": {
+    "http_req_duration": {
+      "type": "trend",
+      "contains": "time",
+      "values": {"avg": 20, "min": 1, "med": 16, "max": 90, "p(90)": 30, "p(95)": 40}
+    },
+    "http_req_failed": {"type": "rate", "contains": "default", "values": {"rate": 0.1, "passes": 200, "fails": 1800}},
+    "http_reqs": {"type": "counter", "contains": "default", "values": {"count": 2000, "rate": 200}}
+  }
+}
+`
+
+func TestParseK6(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseK6(strings.NewReader(k6Output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "measurements", []usl.Measurement{
+		usl.ThroughputAndLatency(100, 10*time.Millisecond),
+		usl.ThroughputAndLatency(180, 20*time.Millisecond),
+	}, got, cmpopts.EquateApprox(1e-9, 0))
+}
+
+func TestParseK6_MissingMetrics(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseK6(strings.NewReader(`{"metrics": {}}`)); !errors.Is(err, errInvalidInput) {
+		t.Errorf("err = %v, want %v", err, errInvalidInput)
+	}
+}
@@ -45,17 +45,104 @@
 //
 // USL will output the data in CSV format on STDOUT.
 //
+// USL can also read the output of Go benchmarks run at several GOMAXPROCS levels, converting the
+// ns/op of each level into throughput:
+//
+//     go test -run=NONE -bench=BenchmarkCache -cpu=1,2,4,8,16,32 > bench.txt
+//     usl bench.txt --format=gobench
+//
+// If the output includes several benchmarks, choose one with --benchmark.
+//
+// Results from other load testing tools can be read with --format, each file containing the
+// results of several runs at different levels of concurrency:
+//
+//     for c in 1 2 4 8 16 32 64; do wrk -c $c -t $c -d 30s http://localhost:8080/; done > wrk.txt
+//     usl wrk.txt --format=wrk
+//
+// The supported formats are the text output of wrk and wrk2 (wrk) and of hey (hey), concatenated
+// vegeta JSON reports (vegeta), concatenated k6 JSON summaries (k6), and JMeter CSV results files,
+// in which samples are grouped by their number of active threads (jtl). Requests which failed are
+// excluded from throughput. For tools which don't record their concurrency, it is derived from
+// throughput and latency.
+//
+// To find the smallest concurrency (e.g. the number of nodes in a cluster) which will handle a
+// target throughput, optionally while keeping mean latency under a ceiling, use the plan command:
+//
+//     usl plan data.csv --throughput=1500 --max-latency=0.05
+//
+// USL will output the concurrency, expected throughput, and expected latency in CSV format on
+// STDOUT.
+//
+// To keep a model for later use, save it along with the measurements it was built from and any
+// labels you'd like to record with it:
+//
+//     usl save data.csv -o model.json -l service=api -l commit=abc123
+//
+// Predictions can then be made from the saved model without the original data:
+//
+//     usl predict model.json 128 256 512
+//
+// To check whether a change has affected a system's scalability, compare models built from
+// measurements taken before and after it:
+//
+//     usl compare before.csv after.csv --at=64,128 --fail-on-regression
+//
+// USL will output the differences in the model parameters, its max throughput, and its latency at
+// the given concurrency levels in CSV format on STDOUT, along with the z-score and p-value of each
+// change. With --fail-on-regression, USL will exit with an error if σ or κ significantly increased
+// or λ or max throughput significantly decreased.
+//
+// To gather measurements in the first place, USL can load test a command by running it repeatedly
+// at a series of concurrency levels:
+//
+//     usl bench -c 1,2,4,8,16,32 --warm-up=5s -d 30s -- ./query.sh > data.csv
+//
+// At each level, USL runs that many copies of the command in a loop, discards the warm-up period,
+// and measures the throughput of the runs which succeeded. It will output the concurrency and
+// throughput of each level in CSV format on STDOUT, suitable for building a model, and progress
+// including mean latency and the number of failed runs on STDERR.
+//
+// HTTP servers can be load tested directly, without the overhead of running a command for each
+// request. Requests which fail or receive a 4xx or 5xx response are counted as errors:
+//
+//     usl bench --url=http://localhost:8080/things --method=POST -H 'Content-Type: application/json' \
+//         --body-file=thing.json > data.csv
+//
+// With --adaptive, the given concurrency levels are only the first ones tested. USL then builds a
+// model after each level and chooses the next one to find the system's peak and to narrow down
+// the model's parameters, stopping when their relative standard errors are within --tolerance:
+//
+//     usl bench --adaptive --max-concurrency=256 --max-steps=15 --url=http://localhost:8080/
+//
+// Closed-loop tests like these understate latency when the system stalls, because stalled runs
+// delay the start of the next ones. To avoid this, run an open-loop test, which starts runs at
+// fixed arrival rates and measures their latency from when they were scheduled to start:
+//
+//     usl bench --rate=10,20,40,80,160,320 --url=http://localhost:8080/ > open.csv
+//
+// The concurrency of each rate is derived from its throughput and latency.
+//
 // For more information, see http://www.perfdynamics.com/Manifesto/USLscalability.html.
 package main
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/codahale/usl"
+	"github.com/codahale/usl/loadgen"
 	"github.com/vdobler/chart"
 	"github.com/vdobler/chart/txtg"
 )
@@ -69,17 +156,14 @@ func main() {
 }
 
 func run() error {
-	//nolint:maligned // ordering of fields matters
 	var cli struct {
-		InputPath         string           `arg:"" type:"existingfile" help:"The CSV file measurements of the system."`
-		Predictions       []float64        `arg:"" optional:"" help:"Predict throughput at the given concurrency levels."`
-		ConcurrencyColumn int              `short:"N" default:"1" help:"The column index of concurrency values."`
-		LatencyColumn     int              `short:"R" default:"2" help:"The column index of latency values."`
-		SkipHeaders       bool             `default:"false" help:"Skip the first line of the file."`
-		Width             int              `short:"W" default:"74" help:"The width of the graph in chars."`
-		Height            int              `short:"H" default:"20" help:"The height of the graph in chars."`
-		NoGraph           bool             `default:"false" help:"Don't display the graph.'"`
-		Version           kong.VersionFlag `help:"Display the application version."`
+		Model   modelCmd         `cmd:"" default:"withargs" help:"Build a model and predict throughput."`
+		Plan    planCmd          `cmd:"" help:"Find the smallest concurrency which meets a throughput target."`
+		Save    saveCmd          `cmd:"" help:"Build a model and save it to a file."`
+		Predict predictCmd       `cmd:"" help:"Predict throughput using a saved model."`
+		Compare compareCmd       `cmd:"" help:"Compare models built from two sets of measurements."`
+		Bench   benchCmd         `cmd:"" help:"Load test a command and print measurements of it."`
+		Version kong.VersionFlag `help:"Display the application version."`
 	}
 
 	ctx := kong.Parse(&cli, kong.Vars{"version": version})
@@ -88,23 +172,344 @@ func run() error {
 		os.Exit(1)
 	}
 
-	measurements, err := parseCSV(cli.InputPath, cli.ConcurrencyColumn, cli.LatencyColumn, cli.SkipHeaders)
+	return ctx.Run()
+}
+
+//nolint:maligned // ordering of fields matters
+type format struct {
+	Format            string `enum:"csv,gobench,wrk,hey,vegeta,k6,jtl" default:"csv" help:"The format of the input."`
+	ConcurrencyColumn int    `short:"N" default:"1" help:"The column index of concurrency values."`
+	LatencyColumn     int    `short:"R" default:"2" help:"The column index of latency values."`
+	SkipHeaders       bool   `default:"false" help:"Skip the first line of the file."`
+	Benchmark         string `help:"The benchmark to use, if the gobench input has several."`
+}
+
+func (f *format) parse(path string) ([]usl.Measurement, error) {
+	var (
+		measurements []usl.Measurement
+		err          error
+	)
+
+	switch f.Format {
+	case "gobench":
+		measurements, err = parseGoBench(path, f.Benchmark)
+	case "wrk":
+		measurements, err = parseFile(path, parseWrk)
+	case "hey":
+		measurements, err = parseFile(path, parseHey)
+	case "vegeta":
+		measurements, err = parseFile(path, parseVegeta)
+	case "k6":
+		measurements, err = parseFile(path, parseK6)
+	case "jtl":
+		measurements, err = parseFile(path, parseJTL)
+	default:
+		measurements, err = parseCSV(path, f.ConcurrencyColumn, f.LatencyColumn, f.SkipHeaders)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %q: %w", path, err)
+	}
+
+	return measurements, nil
+}
+
+// parseFile opens the file and parses it with the given function.
+func parseFile(filename string, parse func(io.Reader) ([]usl.Measurement, error)) ([]usl.Measurement, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	return parse(f)
+}
+
+type input struct {
+	InputPath string `arg:"" type:"existingfile" help:"The CSV file measurements of the system."`
+
+	format
+}
+
+func (in *input) parse() ([]usl.Measurement, error) {
+	return in.format.parse(in.InputPath)
+}
+
+func (in *input) build() ([]usl.Measurement, *usl.Model, error) {
+	measurements, err := in.parse()
 	if err != nil {
-		return fmt.Errorf("error parsing %q: %w", cli.InputPath, err)
+		return nil, nil, err
 	}
 
 	m, err := usl.Build(measurements)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return measurements, m, nil
+}
+
+//nolint:maligned // ordering of fields matters
+type modelCmd struct {
+	input
+
+	Predictions []float64 `arg:"" optional:"" help:"Predict throughput at the given concurrency levels."`
+	Width       int       `short:"W" default:"74" help:"The width of the graph in chars."`
+	Height      int       `short:"H" default:"20" help:"The height of the graph in chars."`
+	NoGraph     bool      `default:"false" help:"Don't display the graph.'"`
+}
+
+func (cmd *modelCmd) Run() error {
+	measurements, m, err := cmd.build()
+	if err != nil {
+		return err
+	}
+
+	printModel(m, measurements, cmd.NoGraph, cmd.Width, cmd.Height)
+
+	printPredictions(m, cmd.Predictions)
+
+	return nil
+}
+
+type planCmd struct {
+	input
+
+	Throughput float64 `short:"X" required:"" help:"The target throughput, in events/sec."`
+	MaxLatency float64 `default:"0" help:"The maximum mean latency, in seconds, if any."`
+}
+
+func (cmd *planCmd) Run() error {
+	measurements, m, err := cmd.build()
+	if err != nil {
+		return err
+	}
+
+	printModel(m, measurements, true, 0, 0)
+
+	p, err := m.PlanForThroughput(cmd.Throughput, cmd.MaxLatency)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d,%f,%f\n", p.Concurrency, p.Throughput, p.Latency)
+
+	return nil
+}
+
+type saveCmd struct {
+	input
+
+	Output string            `short:"o" required:"" help:"The path of the model file to write."`
+	Labels map[string]string `short:"l" help:"Labels to record with the model (e.g. service=api)."`
+}
+
+func (cmd *saveCmd) Run() error {
+	measurements, err := cmd.parse()
+	if err != nil {
+		return err
+	}
+
+	mf, err := usl.NewModelFile(measurements, "usl "+version, cmd.Labels)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(cmd.Output)
+	if err != nil {
+		return err
+	}
+
+	if err := usl.WriteModelFile(f, mf); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+type predictCmd struct {
+	ModelPath   string    `arg:"" type:"existingfile" help:"The model file to use."`
+	Predictions []float64 `arg:"" optional:"" help:"Predict throughput at the given concurrency levels."`
+}
+
+func (cmd *predictCmd) Run() error {
+	f, err := os.Open(cmd.ModelPath)
 	if err != nil {
 		return err
 	}
 
-	printModel(m, measurements, cli.NoGraph, cli.Width, cli.Height)
+	defer func() { _ = f.Close() }()
+
+	mf, err := usl.ReadModelFile(f)
+	if err != nil {
+		return fmt.Errorf("error reading %q: %w", cmd.ModelPath, err)
+	}
+
+	printModel(&mf.Model, mf.Measurements, true, 0, 0)
 
-	printPredictions(m, cli.Predictions)
+	printPredictions(&mf.Model, cmd.Predictions)
 
 	return nil
 }
 
+//nolint:maligned // ordering of fields matters
+type compareCmd struct {
+	BeforePath string `arg:"" type:"existingfile" help:"The CSV file of measurements before the change."`
+	AfterPath  string `arg:"" type:"existingfile" help:"The CSV file of measurements after the change."`
+
+	format
+
+	At               []float64 `help:"Compare latency at the given concurrency levels."`
+	Alpha            float64   `default:"0.05" help:"The significance level of the comparison."`
+	FailOnRegression bool      `default:"false" help:"Exit with an error on a significant regression."`
+}
+
+func (cmd *compareCmd) Run() error {
+	before, err := cmd.parse(cmd.BeforePath)
+	if err != nil {
+		return err
+	}
+
+	after, err := cmd.parse(cmd.AfterPath)
+	if err != nil {
+		return err
+	}
+
+	c, err := usl.Compare(before, after, cmd.At)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("parameter,before,after,change,z,p")
+	printDifference("sigma", c.Sigma)
+	printDifference("kappa", c.Kappa)
+	printDifference("lambda", c.Lambda)
+	printDifference("max_throughput", c.MaxThroughput)
+
+	for _, l := range c.Latencies {
+		printDifference(fmt.Sprintf("latency@%g", l.Concurrency), l.Difference)
+	}
+
+	if c.Regressed(cmd.Alpha) {
+		_, _ = fmt.Fprintf(os.Stderr, "significant scalability regression (α=%g)\n", cmd.Alpha)
+
+		if cmd.FailOnRegression {
+			return errRegression
+		}
+	}
+
+	return nil
+}
+
+func printDifference(name string, d usl.Difference) {
+	fmt.Printf("%s,%g,%g,%g,%g,%g\n", name, d.Before, d.After, d.Change(), d.Z, d.P)
+}
+
+type benchCmd struct {
+	Command        []string      `arg:"" optional:"" help:"The command to run for each operation."`
+	URL            string        `name:"url" help:"Send HTTP requests to the given URL instead of running a command."`
+	Method         string        `default:"GET" help:"The HTTP request method."`
+	Header         []string      `short:"H" help:"HTTP request headers (e.g. 'Accept: text/plain')."`
+	BodyFile       string        `type:"existingfile" help:"The file containing the HTTP request body."`
+	Concurrency    []uint64      `short:"c" default:"1,2,4,8,16,32" help:"The concurrency levels to test."`
+	WarmUp         time.Duration `default:"5s" help:"How long to run each level before measuring it."`
+	Duration       time.Duration `short:"d" default:"30s" help:"How long to measure each level."`
+	Rate           []float64     `xor:"mode" help:"Run an open-loop test at the given arrival rates, in runs/sec."`
+	Adaptive       bool          `xor:"mode" help:"Choose further levels to test using a model of the results."`
+	MaxConcurrency uint64        `default:"1024" help:"The highest concurrency level to test adaptively."`
+	MaxSteps       int           `default:"20" help:"The maximum number of levels to test adaptively."`
+	Tolerance      float64       `default:"0.1" help:"The relative standard error at which to stop testing adaptively."`
+}
+
+func (cmd *benchCmd) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	f, err := cmd.operation()
+	if err != nil {
+		return err
+	}
+
+	onStep := func(step loadgen.Step) {
+		_, _ = fmt.Fprintf(os.Stderr, "concurrency=%g throughput=%f latency=%f errors=%d error_rate=%f\n",
+			step.Concurrency, step.Throughput, step.Latency, step.Errors, step.ErrorRate())
+
+		fmt.Printf("%g,%f\n", step.Concurrency, step.Throughput)
+	}
+
+	if len(cmd.Rate) > 0 {
+		_, err := loadgen.RunOpen(ctx, loadgen.OpenConfig{
+			Rates:    cmd.Rate,
+			WarmUp:   cmd.WarmUp,
+			Duration: cmd.Duration,
+			OnStep:   onStep,
+		}, f)
+
+		return err
+	}
+
+	config := loadgen.Config{
+		Concurrencies: cmd.Concurrency,
+		WarmUp:        cmd.WarmUp,
+		Duration:      cmd.Duration,
+		OnStep:        onStep,
+	}
+
+	if !cmd.Adaptive {
+		_, err := loadgen.Run(ctx, config, f)
+
+		return err
+	}
+
+	_, err = loadgen.RunAdaptive(ctx, loadgen.AdaptiveConfig{
+		Config:         config,
+		MaxConcurrency: cmd.MaxConcurrency,
+		MaxSteps:       cmd.MaxSteps,
+		Tolerance:      cmd.Tolerance,
+	}, f)
+
+	return err
+}
+
+// operation returns an operation which either runs the command or sends the HTTP request.
+func (cmd *benchCmd) operation() (loadgen.Func, error) {
+	if (cmd.URL == "") == (len(cmd.Command) == 0) {
+		return nil, errNoTarget
+	}
+
+	if cmd.URL == "" {
+		return func(ctx context.Context) error {
+			//nolint:gosec // running the given command is the point
+			return exec.CommandContext(ctx, cmd.Command[0], cmd.Command[1:]...).Run()
+		}, nil
+	}
+
+	h := loadgen.HTTP{Method: cmd.Method, URL: cmd.URL, Header: http.Header{}}
+
+	for _, header := range cmd.Header {
+		i := strings.Index(header, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %q", errInvalidHeader, header)
+		}
+
+		h.Header.Add(strings.TrimSpace(header[:i]), strings.TrimSpace(header[i+1:]))
+	}
+
+	if cmd.BodyFile != "" {
+		body, err := ioutil.ReadFile(cmd.BodyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		h.Body = body
+	}
+
+	return h.Func()
+}
+
 func printModel(m *usl.Model, measurements []usl.Measurement, noGraph bool, width, height int) {
 	_, _ = fmt.Fprintf(os.Stderr, "USL parameters: σ=%.6g, κ=%.6g, λ=%.6g\n", m.Sigma, m.Kappa, m.Lambda)
 	_, _ = fmt.Fprintf(os.Stderr, "\tmax throughput: %.6g, max concurrency: %.6g\n", m.MaxThroughput(), m.MaxConcurrency())
@@ -133,7 +538,7 @@ func printModel(m *usl.Model, measurements []usl.Measurement, noGraph bool, widt
 		c := chart.ScatterChart{}
 		c.Key.Pos = "ibr"
 		c.XRange.Fixed(1, m.MaxConcurrency()*2, (m.MaxConcurrency()*2)/10)
-		c.YRange.Fixed(0, m.MaxThroughput()*1.1, 0)
+		c.YRange.Fixed(0, m.MaxThroughput()*1.1, yTicDelta(m.MaxThroughput()*1.1))
 		c.NSamples = len(measurements)
 		c.AddFunc("Predicted", m.ThroughputAtConcurrency,
 			chart.PlotStyleLines, chart.AutoStyle(6, false))
@@ -150,6 +555,20 @@ func printModel(m *usl.Model, measurements []usl.Measurement, noGraph bool, widt
 	_, _ = fmt.Fprintln(os.Stderr)
 }
 
+// yTicDelta returns the delta between tics on a Y axis with the given maximum. The chart package's
+// automatic tic delta doesn't scale with the axis, and allocates a tic for every half-unit of it,
+// so for high throughputs (e.g. from benchmarks) the axis is divided into ten tics instead.
+func yTicDelta(top float64) float64 {
+	if top > maxAutoTicRange {
+		return top / 10
+	}
+
+	return 0
+}
+
+// maxAutoTicRange is the largest Y axis for which the chart package's automatic tic delta is used.
+const maxAutoTicRange = 1e6
+
 func printPredictions(m *usl.Model, args []float64) {
 	for _, n := range args {
 		fmt.Printf("%f,%f\n", n, m.ThroughputAtConcurrency(n))
@@ -183,19 +602,20 @@ func parseCSV(filename string, nCol, rCol int, skipHeaders bool) ([]usl.Measurem
 			return nil, err
 		}
 
-		measurements = append(measurements, usl.ConcurrencyAndThroughput(n, x))
+		// Concurrency may be fractional if it was derived from throughput and latency.
+		measurements = append(measurements, usl.Measurement{Concurrency: n, Throughput: x, Latency: n / x})
 	}
 
 	return measurements, nil
 }
 
 //nolint:goerr113 // not a package
-func parseLine(i, nCol, xCol int, line []string) (uint64, float64, error) {
+func parseLine(i, nCol, xCol int, line []string) (float64, float64, error) {
 	if len(line) != 2 {
 		return 0, 0, fmt.Errorf("invalid line at line %d", i+1)
 	}
 
-	n, err := strconv.ParseUint(line[nCol-1], 10, 64)
+	n, err := strconv.ParseFloat(line[nCol-1], 64)
 	if err != nil {
 		return 0, 0, fmt.Errorf("error at line %d, column %d: %w", i+1, nCol, err)
 	}
@@ -209,3 +629,10 @@ func parseLine(i, nCol, xCol int, line []string) (uint64, float64, error) {
 }
 
 var version = "dev"
+
+var (
+	errRegression    = errors.New("scalability regressed")
+	errNoTarget      = errors.New("either a command or --url is required, but not both")
+	errInvalidHeader = errors.New("invalid header")
+	errInvalidInput  = errors.New("invalid input")
+)
@@ -2,7 +2,11 @@ package main
 
 import (
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/codahale/gubbins/assert"
@@ -65,14 +69,14 @@ func TestMainRun(t *testing.T) {
 	stdout, stderr := fakeMain(t, "example.csv", "1", "2", "3")
 
 	assert.Equal(t, "stdout",
-		`1.000000,89.987785
-2.000000,175.083978
-3.000000,255.626353
+		`1.000000,89.987562
+2.000000,175.083582
+3.000000,255.625825
 `,
 		string(stdout))
 
 	assert.Equal(t, "stderr",
-		`USL parameters: σ=0.0277299, κ=0.000104343, λ=89.9878
+		`USL parameters: σ=0.0277296, κ=0.000104344, λ=89.9876
 	max throughput: 1883.76, max concurrency: 96
 	contention constrained
                                                                           
@@ -100,6 +104,123 @@ func TestMainRun(t *testing.T) {
 		string(stderr))
 }
 
+//nolint:paralleltest // replaces os.Stdout and os.Stderr
+func TestMainRun_HighThroughput(t *testing.T) {
+	_, stderr := fakeMain(t, "bench.txt", "--format=gobench", "--benchmark=BenchmarkCache")
+
+	if !strings.HasPrefix(string(stderr), "USL parameters: ") || !strings.Contains(string(stderr), "Predicted") {
+		t.Errorf("unexpected output: %s", stderr)
+	}
+}
+
+//nolint:paralleltest // replaces os.Stdout and os.Stderr
+func TestMainPlan(t *testing.T) {
+	stdout, _ := fakeMain(t, "plan", "example.csv", "--throughput=1500", "--max-latency=0.05")
+
+	assert.Equal(t, "stdout", "34,1505.584989,0.022583\n", string(stdout))
+}
+
+//nolint:paralleltest // replaces os.Stdout and os.Stderr
+func TestMainSaveAndPredict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+
+	_, _ = fakeMain(t, "save", "example.csv", "-o", path, "-l", "service=api")
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	mf, err := usl.ReadModelFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "labels", map[string]string{"service": "api"}, mf.Labels)
+	assert.Equal(t, "tool", "usl dev", mf.Tool)
+
+	stdout, _ := fakeMain(t, "predict", path, "1", "2", "3")
+
+	assert.Equal(t, "stdout",
+		`1.000000,89.987562
+2.000000,175.083582
+3.000000,255.625825
+`,
+		string(stdout))
+}
+
+//nolint:paralleltest // replaces os.Stdout and os.Stderr
+func TestMainSave_HighThroughput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+
+	_, stderr := fakeMain(t, "save", "bench.txt", "--format=gobench", "--benchmark=BenchmarkCache", "-o", path)
+
+	assert.Equal(t, "stderr", "", string(stderr))
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	mf, err := usl.ReadModelFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mf.Fit.LambdaStdErr <= 0 || mf.Fit.LambdaStdErr >= mf.Model.Lambda {
+		t.Errorf("LambdaStdErr = %v, want between 0 and %v", mf.Fit.LambdaStdErr, mf.Model.Lambda)
+	}
+}
+
+//nolint:paralleltest // replaces os.Stdout and os.Stderr
+func TestMainCompare(t *testing.T) {
+	stdout, stderr := fakeMain(t, "compare", "example.csv", "example.csv", "--at=10", "--fail-on-regression")
+
+	assert.Equal(t, "stdout",
+		`parameter,before,after,change,z,p
+sigma,0.02772964044703663,0.02772964044703663,0,0,1
+kappa,0.0001043436538588415,0.0001043436538588415,0,0,1
+lambda,89.98756223823708,89.98756223823708,0,0,1
+max_throughput,1883.763171168572,1883.763171168572,0,0,1
+latency@10,0.013990352239319527,0.013990352239319527,0,0,1
+`,
+		string(stdout))
+	assert.Equal(t, "stderr", "", string(stderr))
+}
+
+//nolint:paralleltest // replaces os.Stdout and os.Stderr
+func TestMainBench(t *testing.T) {
+	stdout, stderr := fakeMain(t, "bench", "-c", "1,2", "--warm-up=0s", "-d", "50ms", "--", "true")
+
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+
+	assert.Equal(t, "lines", 2, len(lines))
+	assert.Equal(t, "first level", true, strings.HasPrefix(lines[0], "1,"))
+	assert.Equal(t, "second level", true, strings.HasPrefix(lines[1], "2,"))
+	assert.Equal(t, "errors", true, strings.Contains(string(stderr), "errors=0"))
+}
+
+//nolint:paralleltest // replaces os.Stdout and os.Stderr
+func TestMainBenchURL(t *testing.T) {
+	var header string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Test")
+	}))
+	defer server.Close()
+
+	stdout, stderr := fakeMain(t, "bench", "-c", "1", "--warm-up=0s", "-d", "50ms",
+		"--url", server.URL, "-H", "X-Test: yes")
+
+	assert.Equal(t, "level", true, strings.HasPrefix(string(stdout), "1,"))
+	assert.Equal(t, "errors", true, strings.Contains(string(stderr), "errors=0 error_rate=0.000000"))
+	assert.Equal(t, "header", "yes", header)
+}
+
 func fakeMain(t *testing.T, args ...string) ([]byte, []byte) {
 	t.Helper()
 
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/codahale/usl"
+)
+
+// parseVegeta parses one or more vegeta JSON reports, returning a measurement of each. The
+// concurrency of each report is derived from its mean latency and its throughput of successful
+// requests.
+func parseVegeta(r io.Reader) ([]usl.Measurement, error) {
+	var measurements []usl.Measurement
+
+	d := json.NewDecoder(r)
+
+	for {
+		var report struct {
+			Latencies struct {
+				Mean int64 `json:"mean"`
+			} `json:"latencies"`
+			Throughput float64 `json:"throughput"`
+		}
+
+		if err := d.Decode(&report); errors.Is(err, io.EOF) {
+			return measurements, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		measurements = append(measurements,
+			usl.ThroughputAndLatency(report.Throughput, time.Duration(report.Latencies.Mean)))
+	}
+}
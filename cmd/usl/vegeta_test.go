@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+)
+
+const vegetaOutput = `{"latencies":{"total":3000000000,"mean":10000000,"50th":8000000,"90th":15000000,` +
+	`"95th":20000000,"99th":40000000,"max":90000000,"min":1000000},"bytes_in":{"total":0,"mean":0},` +
+	`"duration":3000000000,"wait":1000000,"requests":300,"rate":100,"throughput":99.5,"success":1,` +
+	`"status_codes":{"200":300},"errors":[]}
+{"latencies":{"total":3000000000,"mean":20000000,"50th":16000000,"90th":30000000,` +
+	`"95th":40000000,"99th":80000000,"max":90000000,"min":1000000},"bytes_in":{"total":0,"mean":0},` +
+	`"duration":3000000000,"wait":1000000,"requests":600,"rate":200,"throughput":180,"success":0.9,` +
+	`"status_codes":{"200":540,"500":60},"errors":["500 Internal Server Error"]}
+`
+
+func TestParseVegeta(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseVegeta(strings.NewReader(vegetaOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "measurements", []usl.Measurement{
+		usl.ThroughputAndLatency(99.5, 10*time.Millisecond),
+		usl.ThroughputAndLatency(180, 20*time.Millisecond),
+	}, got)
+}
+
+func TestParseVegeta_Invalid(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseVegeta(strings.NewReader("{")); err == nil {
+		t.Error("should have failed")
+	}
+}
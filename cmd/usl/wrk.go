@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codahale/usl"
+)
+
+// parseWrk parses the output of one or more runs of wrk or wrk2, returning a measurement of each.
+// The concurrency of each run is its number of connections, its latency is its mean latency, and
+// its throughput is its request rate, excluding requests with non-2xx or 3xx responses.
+func parseWrk(r io.Reader) ([]usl.Measurement, error) {
+	var (
+		measurements []usl.Measurement
+		run          wrkRun
+	)
+
+	s := bufio.NewScanner(r)
+	for i := 1; s.Scan(); i++ {
+		line := strings.TrimSpace(s.Text())
+
+		var err error
+
+		switch {
+		case strings.HasPrefix(line, "Running "):
+			run = wrkRun{}
+		case wrkConnections.MatchString(line):
+			run.connections, err = strconv.ParseFloat(wrkConnections.FindStringSubmatch(line)[1], 64)
+		case wrkLatency.MatchString(line):
+			var d time.Duration
+
+			d, err = time.ParseDuration(wrkLatency.FindStringSubmatch(line)[1])
+			run.latency = d.Seconds()
+		case wrkRequests.MatchString(line):
+			run.requests, err = strconv.ParseFloat(wrkRequests.FindStringSubmatch(line)[1], 64)
+		case strings.HasPrefix(line, "Non-2xx or 3xx responses:"):
+			run.failures, err = strconv.ParseFloat(strings.TrimSpace(line[len("Non-2xx or 3xx responses:"):]), 64)
+		case strings.HasPrefix(line, "Requests/sec:"):
+			var x float64
+
+			x, err = strconv.ParseFloat(strings.TrimSpace(line[len("Requests/sec:"):]), 64)
+			if err == nil {
+				var m usl.Measurement
+
+				m, err = run.measurement(x)
+				measurements = append(measurements, m)
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error at line %d: %w", i, err)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return measurements, nil
+}
+
+// wrkRun is the summary of a run of wrk.
+type wrkRun struct {
+	connections, latency, requests, failures float64
+}
+
+// measurement returns a measurement of the run, given its request rate.
+func (run *wrkRun) measurement(x float64) (usl.Measurement, error) {
+	if run.connections == 0 || run.latency == 0 {
+		return usl.Measurement{}, fmt.Errorf("%w: incomplete wrk output", errInvalidInput)
+	}
+
+	if run.requests > 0 {
+		x *= (run.requests - run.failures) / run.requests
+	}
+
+	return usl.Measurement{Concurrency: run.connections, Throughput: x, Latency: run.latency}, nil
+}
+
+//nolint:gochecknoglobals // effectively constant
+var (
+	wrkConnections = regexp.MustCompile(`^\d+ threads and (\d+) connections`)
+	wrkLatency     = regexp.MustCompile(`^Latency\s+([0-9.]+[a-z]+)\s`)
+	wrkRequests    = regexp.MustCompile(`^(\d+) requests in `)
+)
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const wrkOutput = `Running 30s test @ http://127.0.0.1:8080/index.html
+  2 threads and 10 connections
+  Thread Stats   Avg      Stdev     Max   +/- Stdev
+    Latency     2.00ms    0.89ms  12.92ms   93.69%
+    Req/Sec     2.50k   100.00     2.80k    86.54%
+  Latency Distribution
+     50%    1.90ms
+     99%    5.00ms
+  150000 requests in 30.00s, 17.76MB read
+Requests/sec:   5000.00
+Transfer/sec:    606.13KB
+Running 30s test @ http://127.0.0.1:8080/index.html
+  2 threads and 20 connections
+  Thread Stats   Avg      Stdev     Max   +/- Stdev
+    Latency     4.00ms    0.89ms  12.92ms   93.69%
+    Req/Sec     2.50k   100.00     2.80k    86.54%
+  200000 requests in 30.00s, 17.76MB read
+  Non-2xx or 3xx responses: 20000
+Requests/sec:   6666.67
+Transfer/sec:    606.13KB
+`
+
+func TestParseWrk(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseWrk(strings.NewReader(wrkOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "measurements", []usl.Measurement{
+		{Concurrency: 10, Throughput: 5000, Latency: 0.002},
+		{Concurrency: 20, Throughput: 6000, Latency: 0.004},
+	}, got, cmpopts.EquateApprox(1e-6, 0))
+}
+
+func TestParseWrk_Incomplete(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseWrk(strings.NewReader("Requests/sec: 10\n")); !errors.Is(err, errInvalidInput) {
+		t.Errorf("err = %v, want %v", err, errInvalidInput)
+	}
+}
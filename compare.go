@@ -0,0 +1,147 @@
+package usl
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Comparison is the difference between two models, each built from its own set of measurements
+// (e.g. before and after a release).
+type Comparison struct {
+	Before, After Model // The models being compared.
+
+	Sigma         Difference          // The difference in σ.
+	Kappa         Difference          // The difference in κ.
+	Lambda        Difference          // The difference in λ.
+	MaxThroughput Difference          // The difference in MaxThroughput.
+	Latencies     []LatencyDifference // The differences in latency at particular concurrencies.
+}
+
+// Difference is the difference between a value in two models, along with a test of whether the
+// difference is statistically significant.
+type Difference struct {
+	Before float64 // The value in the first model.
+	After  float64 // The value in the second model.
+	Z      float64 // The z-score of the difference, or NaN if it could not be tested.
+	P      float64 // The two-sided p-value of the difference, or NaN if it could not be tested.
+}
+
+// Change returns the difference between the second value and the first.
+func (d Difference) Change() float64 {
+	return d.After - d.Before
+}
+
+// Significant returns true if the difference was tested and its p-value is less than alpha (e.g.
+// 0.05).
+func (d Difference) Significant(alpha float64) bool {
+	return d.P < alpha
+}
+
+// LatencyDifference is the difference in mean latency at a level of concurrency.
+type LatencyDifference struct {
+	Difference
+
+	Concurrency float64 // The level of concurrency.
+}
+
+// Compare builds models from the before and after measurements and compares them, including their
+// mean latencies at each of the given concurrencies.
+//
+// Differences are tested for significance with a z-test using the standard errors of the two
+// fits, which assumes the measurement sets are independent and large enough that the coefficients
+// are approximately normally distributed. The standard errors of max throughput and latency are
+// propagated from the covariance of the coefficients using the delta method.
+func Compare(before, after []Measurement, concurrencies []float64) (*Comparison, error) {
+	mb, cb, err := buildAndEstimate(before)
+	if err != nil {
+		return nil, err
+	}
+
+	ma, ca, err := buildAndEstimate(after)
+	if err != nil {
+		return nil, err
+	}
+
+	// Test the difference in a value, given its gradients with respect to σ, κ, and λ.
+	test := func(before, after float64, gb, ga [3]float64) Difference {
+		return zTest(before, deltaStdErr(cb, gb), after, deltaStdErr(ca, ga))
+	}
+
+	c := &Comparison{
+		Before: *mb,
+		After:  *ma,
+		Sigma:  test(mb.Sigma, ma.Sigma, [3]float64{1, 0, 0}, [3]float64{1, 0, 0}),
+		Kappa:  test(mb.Kappa, ma.Kappa, [3]float64{0, 1, 0}, [3]float64{0, 1, 0}),
+		Lambda: test(mb.Lambda, ma.Lambda, [3]float64{0, 0, 1}, [3]float64{0, 0, 1}),
+		// Max throughput is X(N) at the integral Nmax, which doesn't vary with small changes in the
+		// coefficients, so its gradient is X(N)'s at Nmax.
+		MaxThroughput: test(mb.MaxThroughput(), ma.MaxThroughput(),
+			mb.gradient(mb.MaxConcurrency()), ma.gradient(ma.MaxConcurrency())),
+	}
+
+	for _, n := range concurrencies {
+		c.Latencies = append(c.Latencies, LatencyDifference{
+			Difference: test(mb.LatencyAtConcurrency(n), ma.LatencyAtConcurrency(n),
+				mb.latencyGradient(n), ma.latencyGradient(n)),
+			Concurrency: n,
+		})
+	}
+
+	return c, nil
+}
+
+// Regressed returns true if, at the given significance level, σ or κ increased, or λ or max
+// throughput decreased.
+func (c *Comparison) Regressed(alpha float64) bool {
+	return (c.Sigma.Significant(alpha) && c.Sigma.Change() > 0) ||
+		(c.Kappa.Significant(alpha) && c.Kappa.Change() > 0) ||
+		(c.Lambda.Significant(alpha) && c.Lambda.Change() < 0) ||
+		(c.MaxThroughput.Significant(alpha) && c.MaxThroughput.Change() < 0)
+}
+
+// buildAndEstimate builds a model from the given measurements and estimates the covariance of its
+// coefficients.
+func buildAndEstimate(measurements []Measurement) (*Model, *mat.Dense, error) {
+	m, err := Build(measurements)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cov, err := m.covariance(measurements)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return m, cov, nil
+}
+
+// deltaStdErr returns the standard error of a function of the model's coefficients with the given
+// gradient, √(∇ᵀΣ∇), where Σ is the covariance of the coefficients.
+func deltaStdErr(cov *mat.Dense, gradient [3]float64) float64 {
+	g := mat.NewVecDense(3, gradient[:])
+
+	return math.Sqrt(mat.Inner(g, cov, g))
+}
+
+// latencyGradient returns the partial derivatives of R(N) with respect to σ, κ, and λ.
+func (m *Model) latencyGradient(n float64) [3]float64 {
+	return [3]float64{
+		(n - 1) / m.Lambda,
+		n * (n - 1) / m.Lambda,
+		-m.LatencyAtConcurrency(n) / m.Lambda,
+	}
+}
+
+// zTest returns the difference between two estimates with the given standard errors, along with
+// the z-score and two-sided p-value of the difference.
+func zTest(before, beforeStdErr, after, afterStdErr float64) Difference {
+	z := (after - before) / math.Hypot(beforeStdErr, afterStdErr)
+
+	return Difference{
+		Before: before,
+		After:  after,
+		Z:      z,
+		P:      math.Erfc(math.Abs(z) / math.Sqrt2),
+	}
+}
@@ -0,0 +1,121 @@
+package usl
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestCompare_Same(t *testing.T) {
+	t.Parallel()
+
+	c, err := Compare(measurements, measurements, []float64{10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Sigma.Z", 0.0, c.Sigma.Z)
+	assert.Equal(t, "Sigma.P", 1.0, c.Sigma.P)
+	assert.Equal(t, "Latencies", 1, len(c.Latencies))
+	assert.Equal(t, "Latencies[0].Concurrency", 10.0, c.Latencies[0].Concurrency)
+	assert.Equal(t, "Latencies[0].Change", 0.0, c.Latencies[0].Change())
+	assert.Equal(t, "Latencies[0].P", 1.0, c.Latencies[0].P)
+	assert.Equal(t, "MaxThroughput.P", 1.0, c.MaxThroughput.P)
+	assert.Equal(t, "Regressed", false, c.Regressed(0.05))
+}
+
+func TestCompare_Regression(t *testing.T) {
+	t.Parallel()
+
+	// Simulate a release which doubles coherency costs, with a little noise.
+	worse := &Model{Sigma: 0.02671591, Kappa: 2 * 7.690945e-4, Lambda: 995.6486}
+	after := make([]Measurement, 0, len(measurements))
+
+	for i, m := range measurements {
+		x := worse.ThroughputAtConcurrency(m.Concurrency) * (1 + 0.01*math.Sin(float64(i)))
+		after = append(after, ConcurrencyAndThroughput(uint64(m.Concurrency), x))
+	}
+
+	c, err := Compare(measurements, after, []float64{10, 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Kappa.Significant", true, c.Kappa.Significant(0.05))
+	assert.Equal(t, "Regressed", true, c.Regressed(0.05))
+	assert.Equal(t, "MaxThroughput.Significant", true, c.MaxThroughput.Significant(0.05))
+
+	for _, l := range c.Latencies {
+		if !l.Significant(0.05) || l.Change() <= 0 {
+			t.Errorf("latency should have significantly increased: %+v", l)
+		}
+	}
+
+	if c.MaxThroughput.Change() >= 0 {
+		t.Errorf("max throughput should have decreased: %+v", c.MaxThroughput)
+	}
+
+	// Comparing in the other direction is an improvement, not a regression.
+	c, err = Compare(after, measurements, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Regressed", false, c.Regressed(0.05))
+}
+
+func TestCompare_MaxThroughputStdErr(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	cov, err := m.covariance(measurements)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the delta method's gradient of max throughput against finite differences.
+	got := m.gradient(m.MaxConcurrency())
+
+	for i, h := range []float64{1e-7, 1e-9, 1e-3} {
+		p := *m
+		v := []*float64{&p.Sigma, &p.Kappa, &p.Lambda}[i]
+		*v += h
+
+		assert.Equal(t, fmt.Sprintf("gradient[%d]", i), (p.MaxThroughput()-m.MaxThroughput())/h, got[i],
+			cmpopts.EquateApprox(0.001, 0))
+	}
+
+	if se := deltaStdErr(cov, got); !(se > 0 && se < m.MaxThroughput()) {
+		t.Errorf("standard error = %v, want between 0 and %v", se, m.MaxThroughput())
+	}
+}
+
+func TestComparison_Regressed_MaxThroughput(t *testing.T) {
+	t.Parallel()
+
+	untested := Difference{Z: math.NaN(), P: math.NaN()}
+	c := &Comparison{
+		Sigma:         untested,
+		Kappa:         untested,
+		Lambda:        untested,
+		MaxThroughput: Difference{Before: 2000, After: 1800, Z: -3, P: 0.0027},
+	}
+
+	assert.Equal(t, "Regressed", true, c.Regressed(0.05))
+
+	c.MaxThroughput.Before, c.MaxThroughput.After, c.MaxThroughput.Z = 1800, 2000, 3
+
+	assert.Equal(t, "Regressed", false, c.Regressed(0.05))
+}
+
+func TestDifference_Change(t *testing.T) {
+	t.Parallel()
+
+	d := Difference{Before: 1, After: 3}
+
+	assert.Equal(t, "Change", 2.0, d.Change())
+}
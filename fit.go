@@ -0,0 +1,180 @@
+package usl
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Fit describes how well a model fits a set of measurements.
+type Fit struct {
+	R2               float64 `json:"r2"`                 // The coefficient of determination, R².
+	RMSE             float64 `json:"rmse"`               // The root mean squared error of X(N).
+	DegreesOfFreedom int     `json:"degrees_of_freedom"` // The number of measurements minus three.
+	SigmaStdErr      float64 `json:"sigma_std_err"`      // The standard error of σ.
+	KappaStdErr      float64 `json:"kappa_std_err"`      // The standard error of κ.
+	LambdaStdErr     float64 `json:"lambda_std_err"`     // The standard error of λ.
+}
+
+// Fit returns statistics describing how well the model predicts the throughput of the given
+// measurements.
+//
+// The standard errors of the model's coefficients are estimated from the residual variance and the
+// model's Jacobian at the given measurements. As with Build, if every measurement has a throughput
+// standard deviation, the residuals are weighted accordingly.
+func (m *Model) Fit(measurements []Measurement) (Fit, error) {
+	if len(measurements) < minMeasurements {
+		return Fit{}, ErrInsufficientMeasurements
+	}
+
+	// Calculate the mean throughput.
+	var mean float64
+	for _, v := range measurements {
+		mean += v.Throughput
+	}
+
+	mean /= float64(len(measurements))
+
+	// Calculate the residual and total sums of squares.
+	var sse, sst float64
+
+	for _, v := range measurements {
+		r := v.Throughput - m.ThroughputAtConcurrency(v.Concurrency)
+
+		sse += r * r
+		sst += (v.Throughput - mean) * (v.Throughput - mean)
+	}
+
+	cov, err := m.covariance(measurements)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	return Fit{
+		R2:               1 - sse/sst,
+		RMSE:             math.Sqrt(sse / float64(len(measurements))),
+		DegreesOfFreedom: len(measurements) - 3,
+		SigmaStdErr:      math.Sqrt(cov.At(0, 0)),
+		KappaStdErr:      math.Sqrt(cov.At(1, 1)),
+		LambdaStdErr:     math.Sqrt(cov.At(2, 2)),
+	}, nil
+}
+
+// covariance returns the estimated covariance matrix of σ, κ, and λ, s²(JᵀWJ)⁻¹, where s² is the
+// weighted residual variance of the given measurements.
+func (m *Model) covariance(measurements []Measurement) (*mat.Dense, error) {
+	var chi2 float64
+
+	w := weights(measurements)
+	for i, v := range measurements {
+		r := w[i] * (v.Throughput - m.ThroughputAtConcurrency(v.Concurrency))
+		chi2 += r * r
+	}
+
+	cov, err := inverseInformation(m.jacobian(measurements, w))
+	if err != nil {
+		return nil, err
+	}
+
+	cov.Scale(chi2/float64(len(measurements)-3), cov)
+
+	return cov, nil
+}
+
+// MostInformative returns the candidate concurrency at which an additional measurement would most
+// reduce the uncertainty of the model's coefficients, given the measurements already taken.
+//
+// The candidate chosen is the one which maximizes the determinant of the information matrix JᵀWJ
+// once it is included (i.e. D-optimal design), which is the one at which the model's predictions
+// are currently the least certain.
+func (m *Model) MostInformative(measurements []Measurement, candidates []float64) (float64, error) {
+	if len(measurements) < minMeasurements {
+		return 0, ErrInsufficientMeasurements
+	}
+
+	cov, err := inverseInformation(m.jacobian(measurements, weights(measurements)))
+	if err != nil {
+		return 0, err
+	}
+
+	// Adding a row j to the Jacobian multiplies the determinant of JᵀJ by 1+jᵀ(JᵀJ)⁻¹j, so the most
+	// informative candidate is the one with the greatest leverage.
+	best, bestLeverage := math.NaN(), math.Inf(-1)
+
+	for _, n := range candidates {
+		g := mat.NewVecDense(3, nil)
+		for j, v := range m.gradient(n) {
+			g.SetVec(j, v)
+		}
+
+		if l := mat.Inner(g, cov, g); l > bestLeverage {
+			best, bestLeverage = n, l
+		}
+	}
+
+	return best, nil
+}
+
+// jacobian returns the partial derivatives of X(N) with respect to σ, κ, and λ at each of the given
+// measurements, multiplied by the measurement's weight.
+func (m *Model) jacobian(measurements []Measurement, w []float64) *mat.Dense {
+	jac := mat.NewDense(len(measurements), 3, nil)
+
+	for i, v := range measurements {
+		for j, g := range m.gradient(v.Concurrency) {
+			jac.Set(i, j, w[i]*g)
+		}
+	}
+
+	return jac
+}
+
+// inverseInformation returns (JᵀJ)⁻¹ for the given Jacobian.
+//
+// The columns of the Jacobian differ in scale by as much as λ does from σ and κ, which for systems
+// with high throughput makes JᵀJ too ill-conditioned to invert directly. Each column is scaled to
+// unit length before inverting, and the result is unscaled: if J=J'D, then (JᵀJ)⁻¹=D⁻¹(J'ᵀJ')⁻¹D⁻¹.
+func inverseInformation(jac *mat.Dense) (*mat.Dense, error) {
+	rows, cols := jac.Dims()
+	norms := make([]float64, cols)
+	scaled := mat.NewDense(rows, cols, nil)
+
+	for j := range norms {
+		norms[j] = mat.Norm(jac.ColView(j), 2)
+		if norms[j] == 0 {
+			norms[j] = 1
+		}
+
+		for i := 0; i < rows; i++ {
+			scaled.Set(i, j, jac.At(i, j)/norms[j])
+		}
+	}
+
+	var jtj, cov mat.Dense
+
+	jtj.Mul(scaled.T(), scaled)
+
+	if err := cov.Inverse(&jtj); err != nil {
+		return nil, fmt.Errorf("unable to estimate standard errors: %w", err)
+	}
+
+	for i := 0; i < cols; i++ {
+		for j := 0; j < cols; j++ {
+			cov.Set(i, j, cov.At(i, j)/(norms[i]*norms[j]))
+		}
+	}
+
+	return &cov, nil
+}
+
+// gradient returns the partial derivatives of X(N) with respect to σ, κ, and λ.
+func (m *Model) gradient(n float64) [3]float64 {
+	d := 1 + m.Sigma*(n-1) + m.Kappa*n*(n-1)
+
+	return [3]float64{
+		-m.Lambda * n * (n - 1) / (d * d),
+		-m.Lambda * n * n * (n - 1) / (d * d),
+		n / d,
+	}
+}
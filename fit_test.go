@@ -0,0 +1,100 @@
+package usl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+)
+
+func TestModel_Fit(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	f, err := m.Fit(measurements)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Fit", Fit{
+		R2:               0.9971511351644414,
+		RMSE:             178.87229423792508,
+		DegreesOfFreedom: 29,
+		SigmaStdErr:      0.004493827755889682,
+		KappaStdErr:      8.645445668335304e-05,
+		LambdaStdErr:     28.698363401901474,
+	}, f, epsilon)
+}
+
+func TestModel_Fit_HighThroughput(t *testing.T) {
+	t.Parallel()
+
+	m, measurements := highThroughput()
+
+	f, err := m.Fit(measurements)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, v := range map[string]float64{
+		"SigmaStdErr":  f.SigmaStdErr / m.Sigma,
+		"KappaStdErr":  f.KappaStdErr / m.Kappa,
+		"LambdaStdErr": f.LambdaStdErr / m.Lambda,
+	} {
+		if !(v > 0 && v < 1) {
+			t.Errorf("%s = %v of the coefficient, want between 0 and 1", name, v)
+		}
+	}
+
+	if _, err := m.MostInformative(measurements, []float64{1, 128}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModel_Fit_InsufficientMeasurements(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	if _, err := m.Fit(measurements[:5]); !errors.Is(err, ErrInsufficientMeasurements) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestModel_MostInformative(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	n, err := m.MostInformative(measurements, []float64{1, 8, 32, 128})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "MostInformative", 128.0, n)
+}
+
+func TestModel_MostInformative_InsufficientMeasurements(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	if _, err := m.MostInformative(measurements[:5], []float64{1}); !errors.Is(err, ErrInsufficientMeasurements) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// highThroughput returns a model of a system with a throughput of millions of operations per
+// second, like a Go benchmark, and noisy measurements of it.
+func highThroughput() (*Model, []Measurement) {
+	m := &Model{Sigma: 0.03, Kappa: 0.0006, Lambda: 2.5e7}
+	measurements := make([]Measurement, 0, 7)
+
+	for i, n := range []uint64{1, 2, 4, 8, 16, 32, 64} {
+		noise := 1 + 0.02*float64(i%3-1)
+		measurements = append(measurements, ConcurrencyAndThroughput(n, noise*m.ThroughputAtConcurrency(float64(n))))
+	}
+
+	return m, measurements
+}
@@ -0,0 +1,105 @@
+// Package hdr builds USL measurements from HdrHistogram output.
+//
+// Two formats are supported: the text percentile distribution printed by HdrHistogram's
+// outputPercentileDistribution (and by tools like wrk2), and interval logs (.hlog) of V2 compressed
+// histograms written by HistogramLogWriter. Neither format records the units of its values, so the
+// unit of each recorded value must be provided.
+package hdr
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/codahale/usl"
+)
+
+// Histogram is a distribution of latencies read from HdrHistogram output.
+type Histogram struct {
+	Count uint64  // The total number of recorded latencies.
+	Mean  float64 // The mean latency, in seconds.
+	Max   float64 // The maximum latency, in seconds.
+
+	buckets []bucket // The recorded latencies, ordered by value.
+}
+
+// bucket is a range of equivalent latencies and the number of times they were recorded.
+type bucket struct {
+	value  float64 // The highest equivalent latency in the bucket, in seconds.
+	median float64 // The median equivalent latency in the bucket, in seconds.
+	count  uint64  // The number of latencies recorded in the bucket.
+}
+
+// ValueAtQuantile returns the latency, in seconds, at or below which the given quantile of all
+// recorded latencies fall.
+func (h *Histogram) ValueAtQuantile(q float64) float64 {
+	target := uint64(math.Ceil(q * float64(h.Count)))
+	if target == 0 {
+		target = 1
+	}
+
+	var total uint64
+
+	for _, b := range h.buckets {
+		total += b.count
+		if total >= target {
+			return b.value
+		}
+	}
+
+	return h.Max
+}
+
+// Measurement returns a measurement of a system at the given concurrency whose latencies were
+// recorded over the given duration. The throughput is the number of recorded latencies divided by
+// the duration, and the latency is the histogram's mean.
+func (h *Histogram) Measurement(concurrency float64, d time.Duration) usl.Measurement {
+	return usl.Measurement{
+		Concurrency: concurrency,
+		Throughput:  float64(h.Count) / d.Seconds(),
+		Latency:     h.Mean,
+	}
+}
+
+// LatencyQuantiles returns the latencies at each of the given quantiles of a system at the given
+// concurrency.
+func (h *Histogram) LatencyQuantiles(concurrency float64, quantiles ...float64) usl.LatencyQuantiles {
+	l := usl.LatencyQuantiles{Concurrency: concurrency, Quantiles: make([]usl.Quantile, 0, len(quantiles))}
+
+	for _, q := range quantiles {
+		l.Quantiles = append(l.Quantiles, usl.Quantile{Quantile: q, Latency: h.ValueAtQuantile(q)})
+	}
+
+	return l
+}
+
+// DefaultQuantiles are commonly-used latency quantiles.
+//
+//nolint:gochecknoglobals // effectively constant
+var DefaultQuantiles = []float64{0.5, 0.9, 0.99, 0.999}
+
+// newHistogram returns a histogram of the given buckets, calculating the count, mean, and max from
+// them.
+func newHistogram(buckets []bucket) *Histogram {
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].value < buckets[j].value
+	})
+
+	h := &Histogram{buckets: buckets}
+
+	var sum float64
+
+	for _, b := range buckets {
+		if b.count > 0 {
+			h.Count += b.count
+			h.Max = b.value
+			sum += b.median * float64(b.count)
+		}
+	}
+
+	if h.Count > 0 {
+		h.Mean = sum / float64(h.Count)
+	}
+
+	return h
+}
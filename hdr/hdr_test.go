@@ -0,0 +1,194 @@
+package hdr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+//nolint:gochecknoglobals // fine in tests
+var epsilon = cmpopts.EquateApprox(0.00001, 0)
+
+const distribution = `Running 30s test @ http://127.0.0.1:80/index.html
+  Latency Distribution (HdrHistogram - Recorded Latency)
+       Value   Percentile   TotalCount 1/(1-Percentile)
+
+       1.000 0.000000000000            1         1.00
+       2.000 0.500000000000           50         2.00
+       3.000 0.900000000000           90        10.00
+       4.000 0.990000000000           99       100.00
+      10.000 1.000000000000          100
+#[Mean    =        2.500, StdDeviation   =        1.000]
+#[Max     =       10.000, Total count    =          100]
+#[Buckets =           27, SubBuckets     =         2048]
+----------------------------------------------------------
+  100 requests in 10.00s, 1.00KB read
+`
+
+func TestReadPercentileDistribution(t *testing.T) {
+	t.Parallel()
+
+	h, err := ReadPercentileDistribution(strings.NewReader(distribution), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Count", uint64(100), h.Count)
+	assert.Equal(t, "Mean", 0.0025, h.Mean, epsilon)
+	assert.Equal(t, "Max", 0.010, h.Max, epsilon)
+	assert.Equal(t, "p50", 0.002, h.ValueAtQuantile(0.5), epsilon)
+	assert.Equal(t, "p99", 0.004, h.ValueAtQuantile(0.99), epsilon)
+	assert.Equal(t, "p100", 0.010, h.ValueAtQuantile(1), epsilon)
+}
+
+func TestReadPercentileDistribution_Empty(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadPercentileDistribution(strings.NewReader("nothing here\n"), time.Millisecond)
+	if !errors.Is(err, ErrEmptyHistogram) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestReadLog(t *testing.T) {
+	t.Parallel()
+
+	log := "#[StartTime: 1609459200.000 (seconds since epoch)]\n" +
+		`"StartTimestamp","Interval_Length","Interval_Max","Interval_Compressed_Histogram"` + "\n" +
+		"0.000,1.000,0.003," + encodeLog(t, map[int]int64{1000: 2, 2000: 1}) + "\n" +
+		"Tag=db,1.000,1.000,0.003," + encodeLog(t, map[int]int64{1000: 1, 3000: 1}) + "\n"
+
+	h, err := ReadLog(strings.NewReader(log), time.Microsecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// With 3 significant digits, values below 2048 are exact and values from 2048 to 4095 are
+	// recorded in pairs.
+	assert.Equal(t, "Count", uint64(5), h.Count)
+	assert.Equal(t, "Mean", (1000*3+2000+3001)/5e6, h.Mean, epsilon)
+	assert.Equal(t, "Max", 0.003001, h.Max, epsilon)
+	assert.Equal(t, "p50", 0.001, h.ValueAtQuantile(0.5), epsilon)
+	assert.Equal(t, "p80", 0.002, h.ValueAtQuantile(0.8), epsilon)
+}
+
+func TestReadLog_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadLog(strings.NewReader("0.000,1.000,0.003,AAAA\n"), time.Microsecond)
+	if !errors.Is(err, ErrInvalidHistogram) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHistogram_Measurement(t *testing.T) {
+	t.Parallel()
+
+	h, err := ReadPercentileDistribution(strings.NewReader(distribution), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Measurement", usl.Measurement{
+		Concurrency: 4,
+		Throughput:  10,
+		Latency:     0.0025,
+	}, h.Measurement(4, 10*time.Second), epsilon)
+}
+
+func TestHistogram_LatencyQuantiles(t *testing.T) {
+	t.Parallel()
+
+	h, err := ReadPercentileDistribution(strings.NewReader(distribution), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "LatencyQuantiles", usl.LatencyQuantiles{
+		Concurrency: 4,
+		Quantiles: []usl.Quantile{
+			{Quantile: 0.5, Latency: 0.002},
+			{Quantile: 0.99, Latency: 0.004},
+		},
+	}, h.LatencyQuantiles(4, 0.5, 0.99), epsilon)
+}
+
+// encodeLog returns a base64-encoded V2 compressed histogram with 3 significant digits and the
+// given counts of values. Values must be less than 4096.
+func encodeLog(t *testing.T, counts map[int]int64) string {
+	t.Helper()
+
+	// With 3 significant digits, the first 2048 values each have their own index, and the next 2048
+	// values are mapped in pairs to the next 1024 indexes.
+	indexes := map[int]int64{}
+
+	for v, c := range counts {
+		if v < 2048 {
+			indexes[v] += c
+		} else {
+			indexes[2048+(v-2048)/2] += c
+		}
+	}
+
+	var payload []byte
+
+	for i, zeros := 0, int64(0); i < 3072; i++ {
+		c, ok := indexes[i]
+		if !ok {
+			zeros++
+
+			continue
+		}
+
+		if zeros > 0 {
+			payload = appendZigZag(payload, -zeros)
+			zeros = 0
+		}
+
+		payload = appendZigZag(payload, c)
+	}
+
+	header := make([]byte, headerSize)
+	binary.BigEndian.PutUint32(header[0:], encodingCookie|0x10)
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[12:], 3)
+	binary.BigEndian.PutUint64(header[16:], 1)
+	binary.BigEndian.PutUint64(header[24:], 3600000000)
+
+	compressed := new(bytes.Buffer)
+	zw := zlib.NewWriter(compressed)
+
+	if _, err := zw.Write(append(header, payload...)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := make([]byte, 8, 8+compressed.Len())
+	binary.BigEndian.PutUint32(out[0:], compressedCookie|0x10)
+	binary.BigEndian.PutUint32(out[4:], uint32(compressed.Len()))
+
+	return base64.StdEncoding.EncodeToString(append(out, compressed.Bytes()...))
+}
+
+func appendZigZag(b []byte, v int64) []byte {
+	u := uint64(v<<1) ^ uint64(v>>63)
+
+	for u >= 0x80 {
+		b = append(b, byte(u)|0x80)
+		u >>= 7
+	}
+
+	return append(b, byte(u))
+}
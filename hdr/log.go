@@ -0,0 +1,216 @@
+package hdr
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// ReadLog reads an HdrHistogram interval log (.hlog) and returns a histogram of all of its
+// intervals combined.
+//
+// Each interval is a line of comma-separated fields, optionally prefixed with a tag, the last of
+// which is a base64-encoded V2 compressed histogram. Comment and header lines are ignored. Recorded
+// values are multiplied by unit to convert them to latencies (e.g. time.Microsecond if the values
+// were recorded in microseconds).
+func ReadLog(r io.Reader, unit time.Duration) (*Histogram, error) {
+	counts := map[float64]bucket{}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 16*1024*1024)
+
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, `"`) {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+
+		data, err := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidHistogram, err.Error())
+		}
+
+		buckets, err := decodeCompressed(data, unit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, b := range buckets {
+			c := counts[b.value]
+			c.value, c.median, c.count = b.value, b.median, c.count+b.count
+			counts[b.value] = c
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	buckets := make([]bucket, 0, len(counts))
+	for _, b := range counts {
+		buckets = append(buckets, b)
+	}
+
+	h := newHistogram(buckets)
+	if h.Count == 0 {
+		return nil, ErrEmptyHistogram
+	}
+
+	return h, nil
+}
+
+// decodeCompressed decodes a V2 compressed histogram, returning its non-empty buckets.
+func decodeCompressed(data []byte, unit time.Duration) ([]bucket, error) {
+	if len(data) < 8 || binary.BigEndian.Uint32(data)&^0xf0 != compressedCookie {
+		return nil, fmt.Errorf("%w: unsupported encoding", ErrInvalidHistogram)
+	}
+
+	length := binary.BigEndian.Uint32(data[4:])
+	if uint64(length) > uint64(len(data)-8) {
+		return nil, fmt.Errorf("%w: truncated histogram", ErrInvalidHistogram)
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(data[8 : 8+length]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidHistogram, err.Error())
+	}
+
+	defer func() { _ = zr.Close() }()
+
+	data, err = ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidHistogram, err.Error())
+	}
+
+	return decode(data, unit)
+}
+
+// decode decodes an uncompressed V2 histogram, returning its non-empty buckets.
+func decode(data []byte, unit time.Duration) ([]bucket, error) {
+	if len(data) < headerSize || binary.BigEndian.Uint32(data)&^0xf0 != encodingCookie {
+		return nil, fmt.Errorf("%w: unsupported encoding", ErrInvalidHistogram)
+	}
+
+	payloadLength := binary.BigEndian.Uint32(data[4:])
+	digits := binary.BigEndian.Uint32(data[12:])
+	lowest := binary.BigEndian.Uint64(data[16:])
+
+	if uint64(payloadLength) > uint64(len(data)-headerSize) || digits > 5 || lowest < 1 {
+		return nil, fmt.Errorf("%w: invalid header", ErrInvalidHistogram)
+	}
+
+	l := newLayout(digits, lowest)
+	payload := data[headerSize : headerSize+int(payloadLength)]
+
+	var buckets []bucket
+
+	for index := 0; len(payload) > 0; {
+		v, n := readZigZag(payload)
+		if n == 0 {
+			return nil, fmt.Errorf("%w: invalid counts", ErrInvalidHistogram)
+		}
+
+		payload = payload[n:]
+
+		// Negative values are runs of empty buckets.
+		if v < 0 {
+			index += int(-v)
+
+			continue
+		}
+
+		lo, size := l.valueRange(index)
+		buckets = append(buckets, bucket{
+			value:  float64(lo+size-1) * unit.Seconds(),
+			median: float64(lo+size>>1) * unit.Seconds(),
+			count:  uint64(v),
+		})
+		index++
+	}
+
+	return buckets, nil
+}
+
+// layout describes how an HdrHistogram maps count indexes to ranges of values.
+type layout struct {
+	unitMagnitude               uint
+	subBucketHalfCountMagnitude uint
+	subBucketHalfCount          int
+}
+
+func newLayout(digits uint32, lowest uint64) layout {
+	largest := 2 * uint64(math.Pow10(int(digits)))
+	subBucketCountMagnitude := uint(bits.Len64(largest - 1))
+
+	if subBucketCountMagnitude < 1 {
+		subBucketCountMagnitude = 1
+	}
+
+	return layout{
+		unitMagnitude:               uint(bits.Len64(lowest) - 1),
+		subBucketHalfCountMagnitude: subBucketCountMagnitude - 1,
+		subBucketHalfCount:          1 << (subBucketCountMagnitude - 1),
+	}
+}
+
+// valueRange returns the lowest value which maps to the given count index, and the number of values
+// which map to it.
+func (l layout) valueRange(index int) (lowest, size uint64) {
+	bucketIndex := (index >> l.subBucketHalfCountMagnitude) - 1
+	subBucketIndex := (index & (l.subBucketHalfCount - 1)) + l.subBucketHalfCount
+
+	if bucketIndex < 0 {
+		subBucketIndex -= l.subBucketHalfCount
+		bucketIndex = 0
+	}
+
+	shift := uint(bucketIndex) + l.unitMagnitude
+
+	return uint64(subBucketIndex) << shift, 1 << shift
+}
+
+// readZigZag reads a ZigZag-encoded LEB128 value of at most nine bytes, the last of which holds
+// eight bits. Returns the value and the number of bytes read, or zero bytes if the value is
+// truncated.
+func readZigZag(b []byte) (int64, int) {
+	var v uint64
+
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			v |= uint64(b[i]) << 56
+
+			return int64(v>>1) ^ -int64(v&1), 9
+		}
+
+		v |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i]&0x80 == 0 {
+			return int64(v>>1) ^ -int64(v&1), i + 1
+		}
+	}
+
+	return 0, 0
+}
+
+const (
+	// encodingCookie identifies a V2 encoded histogram, ignoring its word size bits.
+	encodingCookie = 0x1c849303
+
+	// compressedCookie identifies a V2 compressed histogram, ignoring its word size bits.
+	compressedCookie = 0x1c849304
+
+	// headerSize is the size of a V2 encoded histogram's header: cookie, payload length,
+	// normalizing index offset, significant digits, lowest and highest trackable values, and the
+	// integer to double conversion ratio.
+	headerSize = 40
+)
@@ -0,0 +1,107 @@
+package hdr
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadPercentileDistribution reads a histogram from HdrHistogram's text percentile distribution
+// output, in which each row has a value, a percentile, a total count, and optionally 1/(1-P):
+//
+//     Value     Percentile TotalCount 1/(1-Percentile)
+//
+//     0.025 0.000000000000          1           1.00
+//     ...
+//    12.687 1.000000000000    1000000
+//  #[Mean    =        0.500, StdDeviation   =        0.289]
+//  #[Max     =       12.687, Total count    =      1000000]
+//
+// Values are multiplied by unit to convert them to latencies (e.g. time.Millisecond if the values
+// are in milliseconds). Lines which are not part of the distribution, like the rest of wrk2's
+// output, are ignored. If the output includes a mean, it is used instead of one estimated from the
+// distribution.
+func ReadPercentileDistribution(r io.Reader, unit time.Duration) (*Histogram, error) {
+	var (
+		buckets []bucket
+		total   uint64
+		mean    float64
+		hasMean bool
+	)
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+
+		if strings.HasPrefix(line, "#[Mean") {
+			v, err := parseFooterValue(line)
+			if err != nil {
+				return nil, err
+			}
+
+			mean, hasMean = v*unit.Seconds(), true
+
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 || len(fields) > 4 {
+			continue
+		}
+
+		value, err1 := strconv.ParseFloat(fields[0], 64)
+		_, err2 := strconv.ParseFloat(fields[1], 64)
+		count, err3 := strconv.ParseUint(fields[2], 10, 64)
+
+		if err1 != nil || err2 != nil || err3 != nil || count < total {
+			continue
+		}
+
+		v := value * unit.Seconds()
+		buckets = append(buckets, bucket{value: v, median: v, count: count - total})
+		total = count
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if total == 0 {
+		return nil, ErrEmptyHistogram
+	}
+
+	h := newHistogram(buckets)
+	if hasMean {
+		h.Mean = mean
+	}
+
+	return h, nil
+}
+
+// parseFooterValue returns the first value in a footer line like "#[Mean = 0.500, StdDeviation =
+// 0.289]".
+func parseFooterValue(line string) (float64, error) {
+	i, j := strings.Index(line, "="), strings.Index(line, ",")
+	if i < 0 || j < i {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidHistogram, line)
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(line[i+1:j]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidHistogram, line)
+	}
+
+	return v, nil
+}
+
+var (
+	// ErrEmptyHistogram is returned when the input contains no recorded values.
+	ErrEmptyHistogram = errors.New("hdr: histogram is empty")
+
+	// ErrInvalidHistogram is returned when the input cannot be parsed.
+	ErrInvalidHistogram = errors.New("hdr: invalid histogram")
+)
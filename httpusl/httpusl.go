@@ -0,0 +1,87 @@
+// Package httpusl measures the scalability of HTTP services from their live traffic.
+//
+// A Handler wraps an http.Handler and tracks the requests it serves. Each call to Sample returns a
+// measurement of the service since the previous call: its mean concurrency, its throughput, and its
+// mean latency. Measurements sampled at regular intervals over a range of traffic levels can then
+// be used to build a model of the service:
+//
+//     h := httpusl.NewHandler(mux)
+//     go func() {
+//         _ = h.Run(ctx, 10*time.Second, func(m usl.Measurement) {
+//             tracker.Observe(time.Now(), m)
+//         })
+//     }()
+//     _ = http.ListenAndServe(":8080", h)
+package httpusl
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/codahale/usl"
+	"github.com/codahale/usl/recorder"
+)
+
+// Handler is an http.Handler which tracks the requests served by another handler. Its methods are
+// safe for concurrent use.
+type Handler struct {
+	handler  http.Handler
+	recorder *recorder.Recorder
+
+	mu   sync.Mutex
+	prev recorder.Snapshot
+}
+
+// NewHandler returns a Handler which tracks the requests served by the given handler.
+func NewHandler(handler http.Handler) *Handler {
+	return newHandler(handler, time.Now)
+}
+
+func newHandler(handler http.Handler, now func() time.Time) *Handler {
+	r := recorder.NewWithClock(now)
+
+	return &Handler{handler: handler, recorder: r, prev: r.Snapshot()}
+}
+
+// ServeHTTP serves the request with the wrapped handler, recording when it began and ended.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer h.recorder.End(h.recorder.Begin())
+
+	h.handler.ServeHTTP(w, r)
+}
+
+// Sample returns a measurement of the requests served since the previous sample, or since the
+// Handler was created. The concurrency is the mean number of requests in flight during the
+// interval, the throughput is the number of requests completed during the interval divided by its
+// duration, and the latency is the mean latency of those requests. If no requests were completed,
+// the latency is zero.
+func (h *Handler) Sample() usl.Measurement {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	next := h.recorder.Snapshot()
+	m := next.Since(h.prev)
+	h.prev = next
+
+	return m
+}
+
+// Run samples the handler at the given interval until the context is canceled, passing each
+// measurement to f. Intervals in which no requests were completed are skipped.
+func (h *Handler) Run(ctx context.Context, interval time.Duration, f func(usl.Measurement)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if m := h.Sample(); m.Throughput > 0 {
+				f(m)
+			}
+		}
+	}
+}
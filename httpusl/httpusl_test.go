@@ -0,0 +1,70 @@
+package httpusl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+)
+
+func TestHandler_Sample(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	h := newHandler(http.NotFoundHandler(), clock.now)
+
+	a := h.recorder.Begin()
+
+	clock.advance(1 * time.Second)
+
+	b := h.recorder.Begin()
+
+	clock.advance(1 * time.Second)
+	h.recorder.End(a)
+	clock.advance(1 * time.Second)
+	h.recorder.End(b)
+	clock.advance(1 * time.Second)
+
+	assert.Equal(t, "first sample",
+		usl.Measurement{Concurrency: 1, Throughput: 0.5, Latency: 2},
+		h.Sample())
+
+	clock.advance(1 * time.Second)
+
+	assert.Equal(t, "second sample", usl.Measurement{}, h.Sample())
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	h := newHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clock.advance(500 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}), clock.now)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	clock.advance(500 * time.Millisecond)
+
+	assert.Equal(t, "status", http.StatusNoContent, w.Code)
+	assert.Equal(t, "sample",
+		usl.Measurement{Concurrency: 0.5, Throughput: 1, Latency: 0.5},
+		h.Sample())
+}
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
@@ -0,0 +1,130 @@
+package loadgen
+
+import (
+	"context"
+	"math"
+
+	"github.com/codahale/usl"
+)
+
+// AdaptiveConfig describes an adaptive load test, which chooses each concurrency level to test
+// based on a model of the levels tested so far.
+//
+// The configured concurrency levels are tested first. After each step, a provisional model is
+// built from the steps so far. Until the model's peak concurrency has been exceeded, the next level
+// is chosen to bracket the peak; after that, it is the level at which a measurement would most
+// reduce the uncertainty of the model's coefficients. The test stops once the peak is bracketed and
+// the standard errors of σ, κ, and λ are all within the tolerance, or after the maximum number of
+// steps.
+type AdaptiveConfig struct {
+	Config
+
+	MaxConcurrency uint64  // The highest concurrency level to test.
+	MaxSteps       int     // The maximum number of steps, including the initial ones.
+	Tolerance      float64 // The maximum standard error of each coefficient, relative to its value.
+}
+
+// RunAdaptive runs an adaptive closed-loop load test of the operation and returns its steps. If the
+// context is canceled, RunAdaptive returns the steps completed so far along with the context's
+// error.
+func RunAdaptive(ctx context.Context, config AdaptiveConfig, f Func) (Steps, error) {
+	if config.MaxConcurrency == 0 || config.MaxSteps < len(config.Concurrencies) {
+		return nil, ErrInvalidConfig
+	}
+
+	steps, err := Run(ctx, config.Config, f)
+	if err != nil {
+		return steps, err
+	}
+
+	for len(steps) < config.MaxSteps {
+		n, ok := config.next(steps)
+		if !ok {
+			break
+		}
+
+		step, err := config.step(ctx, n, f)
+		if err != nil {
+			return steps, err
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// next returns the next concurrency level to test, or false if the test is done.
+func (c *AdaptiveConfig) next(steps Steps) (uint64, bool) {
+	measurements := steps.Measurements()
+
+	var highest float64
+	for _, m := range measurements {
+		highest = math.Max(highest, m.Concurrency)
+	}
+
+	limit := float64(c.MaxConcurrency)
+
+	// If there isn't enough data for a model, keep doubling the concurrency level.
+	model, err := usl.Build(measurements)
+	if err != nil {
+		return c.expand(highest, highest*2)
+	}
+
+	fit, err := model.Fit(measurements)
+	if err != nil {
+		return c.expand(highest, highest*2)
+	}
+
+	// If the model has no peak (e.g. the measurements so far are superlinear, so κ<0), keep doubling
+	// the concurrency level.
+	peak := model.MaxConcurrency()
+	if math.IsNaN(peak) || math.IsInf(peak, 0) || peak <= 0 {
+		return c.expand(highest, highest*2)
+	}
+
+	// Test beyond the peak concurrency, if it's in range.
+	if bracketed := highest > peak || highest >= limit; !bracketed {
+		return c.expand(highest, math.Max(math.Ceil(peak*1.5), highest*2))
+	}
+
+	if c.precise(model, fit) {
+		return 0, false
+	}
+
+	candidates := make([]float64, 0, int(highest))
+	for n := 1.0; n <= highest; n++ {
+		candidates = append(candidates, n)
+	}
+
+	n, err := model.MostInformative(measurements, candidates)
+	if err != nil || math.IsNaN(n) {
+		return 0, false
+	}
+
+	return c.clamp(n), true
+}
+
+// expand returns the given concurrency level, limited to the maximum, or false if the highest
+// level tested is already the maximum.
+func (c *AdaptiveConfig) expand(highest, n float64) (uint64, bool) {
+	limit := float64(c.MaxConcurrency)
+	if highest >= limit {
+		return 0, false
+	}
+
+	return c.clamp(n), true
+}
+
+// clamp returns the given concurrency level, limited to between one and the maximum.
+func (c *AdaptiveConfig) clamp(n float64) uint64 {
+	return uint64(math.Max(1, math.Min(n, float64(c.MaxConcurrency))))
+}
+
+// precise returns true if the standard errors of the model's coefficients are all within the
+// tolerance.
+func (c *AdaptiveConfig) precise(m *usl.Model, f usl.Fit) bool {
+	return f.SigmaStdErr <= c.Tolerance*math.Abs(m.Sigma) &&
+		f.KappaStdErr <= c.Tolerance*math.Abs(m.Kappa) &&
+		f.LambdaStdErr <= c.Tolerance*math.Abs(m.Lambda)
+}
@@ -0,0 +1,133 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+)
+
+func TestAdaptiveConfig_Next(t *testing.T) {
+	t.Parallel()
+
+	c := AdaptiveConfig{MaxConcurrency: 100, MaxSteps: 20, Tolerance: 0.1}
+
+	// Too few steps to build a model.
+	n, ok := c.next(steps(0, 1, 2, 4))
+	assert.Equal(t, "doubling", uint64(8), n)
+	assert.Equal(t, "doubling ok", true, ok)
+
+	// The peak, at 31, is not yet bracketed.
+	n, ok = c.next(steps(0, 1, 2, 3, 4, 5, 6, 7, 8))
+	assert.Equal(t, "bracketing", uint64(47), n)
+	assert.Equal(t, "bracketing ok", true, ok)
+
+	// The peak is bracketed and the model is precise.
+	_, ok = c.next(steps(0, 1, 2, 3, 4, 5, 6, 7, 8, 47))
+	assert.Equal(t, "done", false, ok)
+
+	// The peak is bracketed but the model is imprecise.
+	n, ok = c.next(steps(0.05, 1, 2, 3, 4, 5, 6, 7, 8, 47))
+	assert.Equal(t, "refining ok", true, ok)
+
+	if n < 1 || n > 47 {
+		t.Errorf("next = %d, want [1,47]", n)
+	}
+
+	// Nothing is left to test.
+	_, ok = c.next(steps(0, 1, 2, 100))
+	assert.Equal(t, "limit", false, ok)
+}
+
+func TestAdaptiveConfig_Next_NoPeak(t *testing.T) {
+	t.Parallel()
+
+	c := AdaptiveConfig{MaxConcurrency: 100, MaxSteps: 20, Tolerance: 0.1}
+
+	// Superlinear measurements, for which the model has κ<0 and no peak.
+	superlinear := usl.Model{Sigma: 0.02, Kappa: -0.001, Lambda: 1000}
+	s := make(Steps, 0, 6)
+
+	for _, n := range []float64{1, 2, 3, 4, 5, 6} {
+		s = append(s, Step{Measurement: usl.ConcurrencyAndThroughput(uint64(n), superlinear.ThroughputAtConcurrency(n))})
+	}
+
+	model, err := usl.Build(s.Measurements())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if model.Kappa >= 0 {
+		t.Fatalf("model = %v, want κ<0", model)
+	}
+
+	n, ok := c.next(s)
+	assert.Equal(t, "doubling", uint64(12), n)
+	assert.Equal(t, "doubling ok", true, ok)
+
+	// The doubled level is limited to the maximum.
+	c.MaxConcurrency = 10
+	n, ok = c.next(s)
+	assert.Equal(t, "limited", uint64(10), n)
+	assert.Equal(t, "limited ok", true, ok)
+}
+
+func TestRunAdaptive(t *testing.T) {
+	t.Parallel()
+
+	results, err := RunAdaptive(context.Background(), AdaptiveConfig{
+		Config: Config{
+			Concurrencies: []uint64{1, 2},
+			Duration:      10 * time.Millisecond,
+		},
+		MaxConcurrency: 8,
+		MaxSteps:       4,
+	}, func(ctx context.Context) error {
+		time.Sleep(time.Millisecond)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var levels []float64
+	for _, s := range results {
+		levels = append(levels, s.Concurrency)
+	}
+
+	assert.Equal(t, "levels", []float64{1, 2, 4, 8}, levels)
+}
+
+func TestRunAdaptive_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := RunAdaptive(context.Background(), AdaptiveConfig{
+		Config: Config{
+			Concurrencies: []uint64{1, 2},
+			Duration:      10 * time.Millisecond,
+		},
+		MaxSteps: 4,
+	}, nil)
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidConfig)
+	}
+}
+
+// steps returns steps at the given concurrency levels of a system with a peak concurrency of 31,
+// with alternating relative errors of the given size.
+func steps(noise float64, concurrencies ...float64) Steps {
+	m := usl.Model{Sigma: 0.02, Kappa: 0.001, Lambda: 1000}
+
+	s := make(Steps, len(concurrencies))
+	for i, n := range concurrencies {
+		x := m.ThroughputAtConcurrency(n) * (1 + noise)
+		noise = -noise
+		s[i] = Step{Measurement: usl.ConcurrencyAndThroughput(uint64(n), x)}
+	}
+
+	return s
+}
@@ -0,0 +1,121 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTP is an operation which sends an HTTP request and reads its response. Requests which fail or
+// which receive a response with a 4xx or 5xx status code are errors.
+type HTTP struct {
+	Method string       // The request method. If empty, GET is used.
+	URL    string       // The request URL.
+	Header http.Header  // The request headers, if any.
+	Body   []byte       // The request body, if any.
+	Client *http.Client // The client with which to send requests. If nil, NewClient is used.
+}
+
+// Func returns an operation which sends the request. The request is validated before any are sent.
+func (h *HTTP) Func() (Func, error) {
+	if _, err := h.newRequest(context.Background()); err != nil {
+		return nil, err
+	}
+
+	client := h.Client
+	if client == nil {
+		client = NewClient()
+	}
+
+	return func(ctx context.Context) error {
+		req, err := h.newRequest(ctx)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = resp.Body.Close() }()
+
+		// Read the entire response, both to include it in the latency and to allow the connection
+		// to be reused.
+		if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+			return err
+		}
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("%w: %s", ErrStatus, resp.Status)
+		}
+
+		return nil
+	}, nil
+}
+
+func (h *HTTP) newRequest(ctx context.Context) (*http.Request, error) {
+	method := h.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if h.Body != nil {
+		body = bytes.NewReader(h.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, h.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range h.Header {
+		req.Header[k] = v
+	}
+
+	// Go treats the Host header specially.
+	if host := h.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	return req, nil
+}
+
+// NewClient returns an HTTP client tuned for load testing. Unlike http.DefaultClient, it keeps
+// enough idle connections open for every worker to reuse its connection instead of opening a new
+// one for each request, doesn't request compressed responses, and doesn't follow redirects.
+func NewClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          0,
+			MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+			DisableCompression:    true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// maxIdleConnsPerHost is the number of idle connections to each host which NewClient's transport
+// keeps open.
+const maxIdleConnsPerHost = 4096
+
+// ErrStatus is returned when an HTTP request receives a response with a 4xx or 5xx status code.
+var ErrStatus = errors.New("loadgen: unsuccessful response status")
@@ -0,0 +1,117 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+)
+
+func TestHTTP_Func(t *testing.T) {
+	t.Parallel()
+
+	var (
+		method, header, body string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		method, header, body = r.Method, r.Header.Get("X-Test"), string(b)
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	h := HTTP{
+		Method: http.MethodPost,
+		URL:    server.URL,
+		Header: http.Header{"X-Test": []string{"yes"}},
+		Body:   []byte("hello"),
+	}
+
+	f, err := h.Func()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := f(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.Equal(t, "method", http.MethodPost, method)
+	assert.Equal(t, "header", "yes", header)
+	assert.Equal(t, "body", "hello", body)
+}
+
+func TestHTTP_Func_Status(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	h := HTTP{URL: server.URL}
+
+	f, err := h.Func()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f(context.Background()); !errors.Is(err, ErrStatus) {
+		t.Errorf("err = %v, want %v", err, ErrStatus)
+	}
+}
+
+func TestHTTP_Func_InvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	h := HTTP{Method: "BAD METHOD", URL: "http://example.com"}
+
+	if _, err := h.Func(); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestHTTP_Run(t *testing.T) {
+	t.Parallel()
+
+	var n int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail every other request.
+		n++
+		if n%2 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	h := HTTP{URL: server.URL}
+
+	f, err := h.Func()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps, err := Run(context.Background(), Config{
+		Concurrencies: []uint64{1},
+		Duration:      50 * time.Millisecond,
+	}, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := steps[0]
+
+	if s.Successes == 0 || s.ErrorRate() < 0.4 || s.ErrorRate() > 0.6 {
+		t.Errorf("unexpected step: %+v", s)
+	}
+
+	assert.Equal(t, "throughput", float64(s.Successes)/0.05, s.Throughput, epsilon)
+}
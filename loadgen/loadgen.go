@@ -0,0 +1,210 @@
+// Package loadgen generates the measurements needed to build a USL model by load testing an
+// operation.
+//
+// A closed-loop load test runs an operation at a series of concurrency levels: at each level, that
+// many workers repeatedly perform the operation, each starting a new one as soon as its previous
+// one ends. After an initial warm-up period, which is discarded, the operations completed by the
+// workers are counted and timed, producing a measurement of the operation at that level:
+//
+//     steps, err := loadgen.Run(ctx, loadgen.Config{
+//         Concurrencies: []uint64{1, 2, 4, 8, 16, 32, 64},
+//         WarmUp:        5 * time.Second,
+//         Duration:      30 * time.Second,
+//     }, func(ctx context.Context) error {
+//         return db.PingContext(ctx)
+//     })
+//     if err != nil {
+//         panic(err)
+//     }
+//
+//     model, err := usl.Build(steps.Measurements())
+//
+// Closed-loop load tests are subject to coordinated omission: when the system stalls, the workers
+// stall with it, and the operations they would have started during the stall are never measured.
+// RunOpen instead starts operations at fixed arrival rates and measures each one's latency from
+// when it was scheduled to start.
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/codahale/usl"
+)
+
+// Func is an operation to be load tested. It should return promptly when the context is canceled.
+type Func func(ctx context.Context) error
+
+// Config describes a load test.
+type Config struct {
+	Concurrencies []uint64      // The concurrency levels at which to run the operation, in order.
+	WarmUp        time.Duration // How long to run each level before measuring it.
+	Duration      time.Duration // How long to measure each level.
+	OnStep        func(Step)    // If non-nil, called with each step as it is completed.
+}
+
+// Step is a measurement of an operation at a single concurrency level.
+//
+// Operations which returned errors are excluded from the measurement's throughput and latency, and
+// are instead counted separately.
+type Step struct {
+	usl.Measurement
+
+	Successes uint64 // The number of operations which succeeded.
+	Errors    uint64 // The number of operations which returned errors.
+}
+
+// ErrorRate returns the fraction of operations which returned errors, or zero if none completed.
+func (s Step) ErrorRate() float64 {
+	if total := s.Successes + s.Errors; total > 0 {
+		return float64(s.Errors) / float64(total)
+	}
+
+	return 0
+}
+
+// Steps are the results of a load test.
+type Steps []Step
+
+// Measurements returns the steps' measurements.
+func (s Steps) Measurements() []usl.Measurement {
+	measurements := make([]usl.Measurement, len(s))
+	for i, step := range s {
+		measurements[i] = step.Measurement
+	}
+
+	return measurements
+}
+
+// Run runs a closed-loop load test of the operation and returns a step for each of the configured
+// concurrency levels. If the context is canceled, Run returns the steps completed so far along with
+// the context's error.
+func Run(ctx context.Context, config Config, f Func) (Steps, error) {
+	if len(config.Concurrencies) == 0 || config.Duration <= 0 || config.WarmUp < 0 {
+		return nil, ErrInvalidConfig
+	}
+
+	steps := make(Steps, 0, len(config.Concurrencies))
+
+	for _, n := range config.Concurrencies {
+		if n == 0 {
+			return steps, ErrInvalidConfig
+		}
+
+		step, err := config.step(ctx, n, f)
+		if err != nil {
+			return steps, err
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// step runs a single step of a load test at the given concurrency level.
+func (c *Config) step(ctx context.Context, n uint64, f Func) (Step, error) {
+	step := runStep(ctx, n, c.WarmUp, c.Duration, f)
+	if err := ctx.Err(); err != nil {
+		return Step{}, err
+	}
+
+	if c.OnStep != nil {
+		c.OnStep(step)
+	}
+
+	return step, nil
+}
+
+// runStep runs n workers until the end of the warm-up and measurement periods, then combines their
+// tallies of the operations completed during the measurement period.
+func runStep(ctx context.Context, n uint64, warmUp, duration time.Duration, f Func) Step {
+	start := time.Now().Add(warmUp)
+	end := start.Add(duration)
+
+	ctx, cancel := context.WithDeadline(ctx, end)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		total   tally
+		workers = int(n)
+	)
+
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			t := work(ctx, start, end, f)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			total.successes += t.successes
+			total.errors += t.errors
+			total.latency += t.latency
+		}()
+	}
+
+	wg.Wait()
+
+	step := Step{
+		Measurement: usl.Measurement{
+			Concurrency: float64(n),
+			Throughput:  float64(total.successes) / duration.Seconds(),
+		},
+		Successes: total.successes,
+		Errors:    total.errors,
+	}
+
+	if total.successes > 0 {
+		step.Latency = total.latency.Seconds() / float64(total.successes)
+	}
+
+	return step
+}
+
+// tally is a worker's count of the operations it completed during a measurement period.
+type tally struct {
+	successes, errors uint64
+	latency           time.Duration // The total latency of the successful operations.
+}
+
+// work performs the operation until the context is done, tallying the operations which ended at or
+// after start and before end.
+func work(ctx context.Context, start, end time.Time, f Func) tally {
+	var t tally
+
+	for ctx.Err() == nil {
+		began := time.Now()
+		err := f(ctx)
+		ended := time.Now()
+
+		if ended.Before(start) || !ended.Before(end) {
+			continue
+		}
+
+		t.record(err, ended.Sub(began))
+	}
+
+	return t
+}
+
+// record tallies an operation which ended with the given error after the given latency.
+func (t *tally) record(err error, latency time.Duration) {
+	if err != nil {
+		t.errors++
+	} else {
+		t.successes++
+		t.latency += latency
+	}
+}
+
+// ErrInvalidConfig is returned when a load test has no concurrency levels, a zero concurrency
+// level, or a non-positive duration.
+var ErrInvalidConfig = errors.New("loadgen: invalid config")
@@ -0,0 +1,119 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	var steps []Step
+
+	results, err := Run(context.Background(), Config{
+		Concurrencies: []uint64{1, 2, 4},
+		WarmUp:        10 * time.Millisecond,
+		Duration:      100 * time.Millisecond,
+		OnStep: func(step Step) {
+			steps = append(steps, step)
+		},
+	}, func(ctx context.Context) error {
+		time.Sleep(time.Millisecond)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "steps", []Step(results), steps)
+
+	for i, n := range []float64{1, 2, 4} {
+		s := results[i]
+
+		assert.Equal(t, "concurrency", n, s.Concurrency)
+		assert.Equal(t, "errors", uint64(0), s.Errors)
+
+		if s.Throughput <= 0 || s.Latency < 0.001 {
+			t.Errorf("unexpected step: %+v", s)
+		}
+	}
+
+	assert.Equal(t, "measurements", results[2].Measurement, results.Measurements()[2])
+}
+
+func TestRun_Errors(t *testing.T) {
+	t.Parallel()
+
+	steps, err := Run(context.Background(), Config{
+		Concurrencies: []uint64{2},
+		Duration:      10 * time.Millisecond,
+	}, func(ctx context.Context) error {
+		time.Sleep(time.Millisecond)
+
+		return errTest
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "throughput", 0.0, steps[0].Throughput)
+	assert.Equal(t, "latency", 0.0, steps[0].Latency)
+
+	if steps[0].Errors == 0 {
+		t.Error("no errors were counted")
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	steps, err := Run(ctx, Config{
+		Concurrencies: []uint64{1, 2},
+		Duration:      10 * time.Millisecond,
+		OnStep: func(step Step) {
+			cancel()
+		},
+	}, func(ctx context.Context) error {
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+
+	assert.Equal(t, "steps", 1, len(steps))
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []Config{
+		{Duration: time.Second},
+		{Concurrencies: []uint64{1}},
+		{Concurrencies: []uint64{0}, Duration: time.Second},
+	} {
+		if _, err := Run(context.Background(), config, nil); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Run(%+v) = %v, want %v", config, err, ErrInvalidConfig)
+		}
+	}
+}
+
+func TestStep_ErrorRate(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "no operations", 0.0, Step{}.ErrorRate())
+	assert.Equal(t, "some errors", 0.25, Step{Successes: 3, Errors: 1}.ErrorRate())
+}
+
+var errTest = errors.New("test")
+
+//nolint:gochecknoglobals // fine in tests
+var epsilon = cmpopts.EquateApprox(0.00001, 0)
@@ -0,0 +1,148 @@
+package loadgen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codahale/usl"
+)
+
+// OpenConfig describes an open-loop load test, in which operations are started at a fixed rate
+// regardless of how many are already in flight.
+//
+// Unlike a closed-loop test, in which a slow operation delays the start of the next one and so
+// hides the delay it would have caused (i.e. coordinated omission), each operation's latency is
+// measured from the time at which it was scheduled to start. Each step measures the operations
+// scheduled during its measurement period, including those which end after it: when the system is
+// saturated, those are the slowest ones.
+type OpenConfig struct {
+	Rates    []float64     // The arrival rates at which to start operations, in operations/sec, in order.
+	WarmUp   time.Duration // How long to run each rate before measuring it.
+	Duration time.Duration // How long to measure each rate.
+	Grace    time.Duration // How long operations may run after the measurement period. If zero, Duration.
+	OnStep   func(Step)    // If non-nil, called with each step as it is completed.
+}
+
+// RunOpen runs an open-loop load test of the operation and returns a step for each of the
+// configured arrival rates. Each step's measurement is built from its throughput and latency with
+// Little's Law. If the context is canceled, RunOpen returns the steps completed so far along with
+// the context's error.
+func RunOpen(ctx context.Context, config OpenConfig, f Func) (Steps, error) {
+	if len(config.Rates) == 0 || config.Duration <= 0 || config.WarmUp < 0 || config.Grace < 0 {
+		return nil, ErrInvalidConfig
+	}
+
+	grace := config.Grace
+	if grace == 0 {
+		grace = config.Duration
+	}
+
+	steps := make(Steps, 0, len(config.Rates))
+
+	for _, rate := range config.Rates {
+		if rate <= 0 {
+			return steps, ErrInvalidConfig
+		}
+
+		step := runOpenStep(ctx, rate, config.WarmUp, config.Duration, grace, f)
+		if err := ctx.Err(); err != nil {
+			return steps, err
+		}
+
+		steps = append(steps, step)
+
+		if config.OnStep != nil {
+			config.OnStep(step)
+		}
+	}
+
+	return steps, nil
+}
+
+// runOpenStep starts operations at the given rate until the end of the warm-up and measurement
+// periods, waits for those in flight to end, and tallies the operations scheduled during the
+// measurement period. Operations are canceled after the grace period, and any which end after it
+// are counted as errors.
+func runOpenStep(ctx context.Context, rate float64, warmUp, duration, grace time.Duration, f Func) Step {
+	began := time.Now()
+	start := began.Add(warmUp)
+	end := start.Add(duration)
+	deadline := end.Add(grace)
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total tally
+	)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for i := 0; ; i++ {
+		// Schedule each operation relative to the beginning of the step, so that delays in starting
+		// one don't accumulate.
+		scheduled := began.Add(time.Duration(float64(i) / rate * float64(time.Second)))
+		if !scheduled.Before(end) {
+			break
+		}
+
+		if d := time.Until(scheduled); d > 0 {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+
+			timer.Reset(d)
+
+			select {
+			case <-ctx.Done():
+			case <-timer.C:
+			}
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := f(ctx)
+			ended := time.Now()
+
+			if scheduled.Before(start) {
+				return
+			}
+
+			if err == nil && ended.After(deadline) {
+				err = context.DeadlineExceeded
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			total.record(err, ended.Sub(scheduled))
+		}()
+	}
+
+	wg.Wait()
+
+	var latency time.Duration
+	if total.successes > 0 {
+		latency = total.latency / time.Duration(total.successes)
+	}
+
+	return Step{
+		Measurement: usl.ThroughputAndLatency(float64(total.successes)/duration.Seconds(), latency),
+		Successes:   total.successes,
+		Errors:      total.errors,
+	}
+}
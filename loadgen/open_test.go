@@ -0,0 +1,116 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+)
+
+func TestRunOpen(t *testing.T) {
+	t.Parallel()
+
+	var steps []Step
+
+	results, err := RunOpen(context.Background(), OpenConfig{
+		Rates:    []float64{100, 200},
+		WarmUp:   50 * time.Millisecond,
+		Duration: 500 * time.Millisecond,
+		OnStep: func(step Step) {
+			steps = append(steps, step)
+		},
+	}, func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "steps", []Step(results), steps)
+
+	for i, rate := range []float64{100, 200} {
+		s := results[i]
+
+		// The operations overlap, so the concurrency is well above one.
+		if math.Abs(s.Throughput-rate)/rate > 0.2 || s.Latency < 0.02 || s.Concurrency < rate*0.02*0.8 {
+			t.Errorf("unexpected step at %v/sec: %+v", rate, s)
+		}
+	}
+}
+
+func TestRunOpen_Saturated(t *testing.T) {
+	t.Parallel()
+
+	// The system can complete one operation every 10ms, so at 200/sec a queue builds up and the
+	// operations scheduled last end well after the measurement period.
+	capacity := make(chan struct{}, 1)
+
+	results, err := RunOpen(context.Background(), OpenConfig{
+		Rates:    []float64{200},
+		WarmUp:   50 * time.Millisecond,
+		Duration: 300 * time.Millisecond,
+		Grace:    5 * time.Second,
+	}, func(ctx context.Context) error {
+		capacity <- struct{}{}
+		defer func() { <-capacity }()
+
+		time.Sleep(10 * time.Millisecond)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := results[0]
+
+	// Every operation scheduled during the measurement period is counted, including the slowest.
+	assert.Equal(t, "operations", uint64(60), s.Successes+s.Errors)
+	assert.Equal(t, "errors", uint64(0), s.Errors)
+
+	// The queue grows by 100 operations/sec, so the last operations wait 350ms and the mean is
+	// over 200ms.
+	if s.Latency < 0.2 {
+		t.Errorf("Latency = %v, want >= 0.2", s.Latency)
+	}
+}
+
+func TestRunOpen_Grace(t *testing.T) {
+	t.Parallel()
+
+	results, err := RunOpen(context.Background(), OpenConfig{
+		Rates:    []float64{100},
+		Duration: 100 * time.Millisecond,
+		Grace:    time.Millisecond,
+	}, func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "successes", uint64(0), results[0].Successes)
+	assert.Equal(t, "errors", uint64(10), results[0].Errors)
+}
+
+func TestRunOpen_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []OpenConfig{
+		{Duration: time.Second},
+		{Rates: []float64{1}},
+		{Rates: []float64{0}, Duration: time.Second},
+		{Rates: []float64{1}, Duration: time.Second, Grace: -time.Second},
+	} {
+		if _, err := RunOpen(context.Background(), config, nil); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("RunOpen(%+v) = %v, want %v", config, err, ErrInvalidConfig)
+		}
+	}
+}
@@ -2,15 +2,25 @@ package usl
 
 import (
 	"fmt"
+	"math"
 	"time"
 )
 
 // Measurement is a simultaneous measurement of at least two of the parameters of Little's Law:
 // concurrency, throughput, and latency. The third parameter is inferred from the other two.
+//
+// A measurement may also carry the standard deviations of its parameters, which are used to weight
+// it when building a model. Latency quantiles (e.g. p99) are recorded separately as
+// LatencyQuantiles.
 type Measurement struct {
-	Concurrency float64 // The average number of concurrent events.
-	Throughput  float64 // The long-term average arrival rate of events, in events/sec.
-	Latency     float64 // The average duration of events in seconds.
+	Concurrency float64 `json:"concurrency"` // The average number of concurrent events.
+	Throughput  float64 `json:"throughput"`  // The long-term average arrival rate of events, in events/sec.
+	Latency     float64 `json:"latency"`     // The average duration of events in seconds.
+
+	ConcurrencyStdDev float64 `json:"concurrency_std_dev,omitempty"` // The standard deviation of concurrency.
+	ThroughputStdDev  float64 `json:"throughput_std_dev,omitempty"`  // The standard deviation of throughput.
+	LatencyStdDev     float64 `json:"latency_std_dev,omitempty"`     // The standard deviation of latency.
+	Samples           uint64  `json:"samples,omitempty"`             // The number of samples averaged, if known.
 }
 
 func (m *Measurement) String() string {
@@ -46,3 +56,94 @@ func ThroughputAndLatency(x float64, r time.Duration) Measurement {
 		Latency:     r.Seconds(),     // W
 	}
 }
+
+// ConcurrencyAndLatencyWithStdDev returns a measurement of a system's latency at a given level of
+// concurrency, along with the standard deviations of both and the number of samples they were
+// averaged from. The throughput of the system and its standard deviation are derived via Little's
+// Law, assuming the errors in concurrency and latency are independent.
+func ConcurrencyAndLatencyWithStdDev(n uint64, nStdDev float64, r, rStdDev time.Duration, samples uint64) Measurement {
+	m := ConcurrencyAndLatency(n, r)
+	m.ConcurrencyStdDev = nStdDev
+	m.LatencyStdDev = rStdDev.Seconds()
+	m.ThroughputStdDev = propagate(m.Throughput, m.Concurrency, nStdDev, m.Latency, m.LatencyStdDev)
+	m.Samples = samples
+
+	return m
+}
+
+// ConcurrencyAndThroughputWithStdDev returns a measurement of a system's throughput at a given
+// level of concurrency, along with the standard deviations of both and the number of samples they
+// were averaged from. The latency of the system and its standard deviation are derived via Little's
+// Law, assuming the errors in concurrency and throughput are independent.
+func ConcurrencyAndThroughputWithStdDev(n uint64, nStdDev, x, xStdDev float64, samples uint64) Measurement {
+	m := ConcurrencyAndThroughput(n, x)
+	m.ConcurrencyStdDev = nStdDev
+	m.ThroughputStdDev = xStdDev
+	m.LatencyStdDev = propagate(m.Latency, m.Concurrency, nStdDev, m.Throughput, xStdDev)
+	m.Samples = samples
+
+	return m
+}
+
+// ThroughputAndLatencyWithStdDev returns a measurement of a system's latency at a given level of
+// throughput, along with the standard deviations of both and the number of samples they were
+// averaged from. The concurrency of the system and its standard deviation are derived via Little's
+// Law, assuming the errors in throughput and latency are independent.
+func ThroughputAndLatencyWithStdDev(x, xStdDev float64, r, rStdDev time.Duration, samples uint64) Measurement {
+	m := ThroughputAndLatency(x, r)
+	m.ThroughputStdDev = xStdDev
+	m.LatencyStdDev = rStdDev.Seconds()
+	m.ConcurrencyStdDev = propagate(m.Concurrency, m.Throughput, xStdDev, m.Latency, m.LatencyStdDev)
+	m.Samples = samples
+
+	return m
+}
+
+// propagate returns the standard deviation of v, the product or quotient of a and b, given their
+// standard deviations. For both products and quotients of independent variables, the relative
+// errors add in quadrature.
+func propagate(v, a, aStdDev, b, bStdDev float64) float64 {
+	return math.Abs(v) * math.Hypot(aStdDev/a, bStdDev/b)
+}
+
+// Timing is the start and end time of a single event.
+type Timing struct {
+	Start time.Time // When the event started.
+	End   time.Time // When the event ended.
+}
+
+// FromTimings returns a measurement of a system over the window between start and end, given the
+// timings of the events which occurred during it. Events which started before or ended after the
+// window are included in proportion to their overlap with it.
+//
+// The mean concurrency is the total time events spent in flight during the window divided by its
+// duration, and the throughput is the number of events which ended during the window divided by its
+// duration. The latency is derived via Little's Law. If no events ended during the window, the
+// latency is not finite.
+func FromTimings(start, end time.Time, timings []Timing) Measurement {
+	window := end.Sub(start).Seconds()
+
+	var busy, completed float64
+
+	for _, t := range timings {
+		// Clip the event to the window.
+		s, e := t.Start, t.End
+		if s.Before(start) {
+			s = start
+		}
+
+		if e.After(end) {
+			e = end
+		}
+
+		if e.After(s) {
+			busy += e.Sub(s).Seconds()
+		}
+
+		if !t.End.Before(start) && t.End.Before(end) {
+			completed++
+		}
+	}
+
+	return littlesLaw(busy/window, completed/window, math.NaN())
+}
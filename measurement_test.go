@@ -1,6 +1,7 @@
 package usl
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -15,6 +16,14 @@ func TestMeasurement_String(t *testing.T) {
 	assert.Equal(t, "String", "(n=1,x=2,r=3)", m.String())
 }
 
+func TestMeasurement_Comparable(t *testing.T) {
+	t.Parallel()
+
+	seen := map[Measurement]bool{ConcurrencyAndThroughput(1, 2): true}
+
+	assert.Equal(t, "seen", true, seen[ConcurrencyAndThroughput(1, 2)])
+}
+
 func TestConcurrencyAndLatency(t *testing.T) {
 	t.Parallel()
 
@@ -44,3 +53,58 @@ func TestThroughputAndLatency(t *testing.T) {
 	assert.Equal(t, "Latency", 0.6, m.Latency, epsilon)
 	assert.Equal(t, "Throughput", 5.0, m.Throughput, epsilon)
 }
+
+func TestFromTimings(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+
+	m := FromTimings(start, at(1000), []Timing{
+		{Start: at(-500), End: at(100)},  // 100ms in window, completed
+		{Start: at(0), End: at(400)},     // 400ms in window, completed
+		{Start: at(200), End: at(700)},   // 500ms in window, completed
+		{Start: at(600), End: at(1000)},  // 400ms in window, completed after window
+		{Start: at(800), End: at(1600)},  // 200ms in window, in flight
+		{Start: at(-900), End: at(-100)}, // outside of window
+	})
+
+	assert.Equal(t, "Concurrency", 1.6, m.Concurrency, epsilon)
+	assert.Equal(t, "Throughput", 3.0, m.Throughput, epsilon)
+	assert.Equal(t, "Latency", 1.6/3.0, m.Latency, epsilon)
+}
+
+func TestConcurrencyAndLatencyWithStdDev(t *testing.T) {
+	t.Parallel()
+
+	m := ConcurrencyAndLatencyWithStdDev(3, 0.3, 600*time.Millisecond, 60*time.Millisecond, 10)
+
+	assert.Equal(t, "Throughput", 5.0, m.Throughput, epsilon)
+	assert.Equal(t, "ConcurrencyStdDev", 0.3, m.ConcurrencyStdDev, epsilon)
+	assert.Equal(t, "LatencyStdDev", 0.06, m.LatencyStdDev, epsilon)
+	assert.Equal(t, "ThroughputStdDev", 5*math.Sqrt2*0.1, m.ThroughputStdDev, epsilon)
+	assert.Equal(t, "Samples", uint64(10), m.Samples)
+}
+
+func TestConcurrencyAndThroughputWithStdDev(t *testing.T) {
+	t.Parallel()
+
+	m := ConcurrencyAndThroughputWithStdDev(3, 0, 5, 0.5, 10)
+
+	assert.Equal(t, "Latency", 0.6, m.Latency, epsilon)
+	assert.Equal(t, "ThroughputStdDev", 0.5, m.ThroughputStdDev, epsilon)
+	assert.Equal(t, "LatencyStdDev", 0.06, m.LatencyStdDev, epsilon)
+	assert.Equal(t, "Samples", uint64(10), m.Samples)
+}
+
+func TestThroughputAndLatencyWithStdDev(t *testing.T) {
+	t.Parallel()
+
+	m := ThroughputAndLatencyWithStdDev(5, 0.5, 600*time.Millisecond, 0, 10)
+
+	assert.Equal(t, "Concurrency", 3.0, m.Concurrency, epsilon)
+	assert.Equal(t, "ConcurrencyStdDev", 0.3, m.ConcurrencyStdDev, epsilon)
+	assert.Equal(t, "Samples", uint64(10), m.Samples)
+}
@@ -0,0 +1,263 @@
+package usl
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Measurements is a set of measurements of a system. It can be passed directly to Build, and
+// marshals to and from JSON as an array of measurements.
+type Measurements []Measurement
+
+// Sort sorts the measurements by concurrency, preserving the order of measurements with equal
+// concurrency.
+func (ms Measurements) Sort() {
+	sort.SliceStable(ms, func(i, j int) bool {
+		return ms[i].Concurrency < ms[j].Concurrency
+	})
+}
+
+// Finite returns the measurements whose concurrency, throughput, and latency are all finite
+// numbers.
+func (ms Measurements) Finite() Measurements {
+	finite := make(Measurements, 0, len(ms))
+
+	for _, m := range ms {
+		if isFinite(m.Concurrency) && isFinite(m.Throughput) && isFinite(m.Latency) {
+			finite = append(finite, m)
+		}
+	}
+
+	return finite
+}
+
+// Summary describes a set of measurements taken at the same level of concurrency.
+type Summary struct {
+	Concurrency        float64     // The level of concurrency.
+	Count              int         // The number of measurements.
+	Mean               Measurement // The mean throughput and latency, with standard deviations.
+	Median             Measurement // The median throughput and latency.
+	ThroughputVariance float64     // The sample variance of throughput.
+	LatencyVariance    float64     // The sample variance of latency.
+}
+
+// Summarize groups the measurements by concurrency and returns a summary of each group, ordered by
+// concurrency.
+func (ms Measurements) Summarize() []Summary {
+	sorted := make(Measurements, len(ms))
+	copy(sorted, ms)
+	sorted.Sort()
+
+	var summaries []Summary
+
+	for i := 0; i < len(sorted); {
+		j := i + 1
+		for j < len(sorted) && sorted[j].Concurrency == sorted[i].Concurrency {
+			j++
+		}
+
+		summaries = append(summaries, summarize(sorted[i:j]))
+		i = j
+	}
+
+	return summaries
+}
+
+// Aggregate is a method of combining measurements taken at the same level of concurrency.
+type Aggregate int
+
+const (
+	// AggregateMean combines measurements using their mean throughput and latency.
+	AggregateMean Aggregate = iota
+
+	// AggregateMedian combines measurements using their median throughput and latency.
+	AggregateMedian
+)
+
+// Merge combines measurements taken at the same level of concurrency into a single measurement
+// using the given aggregate, and returns the results ordered by concurrency. Means carry the
+// standard deviations of throughput and latency and the number of measurements combined, which
+// Build uses to weight them.
+func (ms Measurements) Merge(agg Aggregate) Measurements {
+	summaries := ms.Summarize()
+	merged := make(Measurements, len(summaries))
+
+	for i, s := range summaries {
+		if agg == AggregateMedian {
+			merged[i] = s.Median
+		} else {
+			merged[i] = s.Mean
+		}
+	}
+
+	return merged
+}
+
+// WriteCSV writes the measurements to w as CSV with a header row of concurrency, throughput, and
+// latency.
+func (ms Measurements) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{colConcurrency, colThroughput, colLatency}); err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		if err := cw.Write([]string{
+			strconv.FormatFloat(m.Concurrency, 'g', -1, 64),
+			strconv.FormatFloat(m.Throughput, 'g', -1, 64),
+			strconv.FormatFloat(m.Latency, 'g', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// ReadCSV reads measurements from CSV with a header row. The header must name at least two of the
+// concurrency, throughput, and latency columns, in any order; if only two are present, the third is
+// derived via Little's Law. Other columns are ignored.
+func ReadCSV(r io.Reader) (Measurements, error) {
+	lines, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrMissingColumns
+	}
+
+	cols := map[string]int{}
+	for i, name := range lines[0] {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	ms := make(Measurements, 0, len(lines)-1)
+
+	for i, line := range lines[1:] {
+		var v [3]float64
+
+		present := 0
+
+		for j, name := range []string{colConcurrency, colThroughput, colLatency} {
+			col, ok := cols[name]
+			if !ok {
+				v[j] = math.NaN()
+
+				continue
+			}
+
+			if v[j], err = strconv.ParseFloat(strings.TrimSpace(line[col]), 64); err != nil {
+				return nil, fmt.Errorf("error at line %d, column %d: %w", i+2, col+1, err)
+			}
+
+			present++
+		}
+
+		if present < 2 {
+			return nil, ErrMissingColumns
+		}
+
+		ms = append(ms, littlesLaw(v[0], v[1], v[2]))
+	}
+
+	return ms, nil
+}
+
+// littlesLaw returns a measurement with any missing (NaN) parameter derived from the other two.
+func littlesLaw(n, x, r float64) Measurement {
+	switch {
+	case math.IsNaN(n):
+		n = x * r // L=λW
+	case math.IsNaN(x):
+		x = n / r // λ=L/W
+	case math.IsNaN(r):
+		r = n / x // W=L/λ
+	}
+
+	return Measurement{Concurrency: n, Throughput: x, Latency: r}
+}
+
+func summarize(ms Measurements) Summary {
+	x := make([]float64, len(ms))
+	r := make([]float64, len(ms))
+
+	for i, m := range ms {
+		x[i] = m.Throughput
+		r[i] = m.Latency
+	}
+
+	n := ms[0].Concurrency
+	xMean, xVar := meanVariance(x)
+	rMean, rVar := meanVariance(r)
+
+	return Summary{
+		Concurrency: n,
+		Count:       len(ms),
+		Mean: Measurement{
+			Concurrency:      n,
+			Throughput:       xMean,
+			Latency:          rMean,
+			ThroughputStdDev: math.Sqrt(xVar),
+			LatencyStdDev:    math.Sqrt(rVar),
+			Samples:          uint64(len(ms)),
+		},
+		Median:             Measurement{Concurrency: n, Throughput: median(x), Latency: median(r)},
+		ThroughputVariance: xVar,
+		LatencyVariance:    rVar,
+	}
+}
+
+// meanVariance returns the mean and sample variance of the given values.
+func meanVariance(values []float64) (mean, variance float64) {
+	for _, v := range values {
+		mean += v
+	}
+
+	mean /= float64(len(values))
+
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return mean, variance / float64(len(values)-1)
+}
+
+// median returns the median of the given values, reordering them in the process.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+
+	return values[mid]
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+const (
+	colConcurrency = "concurrency"
+	colThroughput  = "throughput"
+	colLatency     = "latency"
+)
+
+// ErrMissingColumns is returned when CSV data does not have at least two of the concurrency,
+// throughput, and latency columns.
+var ErrMissingColumns = errors.New("usl: need at least two of concurrency, throughput, and latency")
@@ -0,0 +1,170 @@
+package usl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+)
+
+func TestMeasurements_Sort(t *testing.T) {
+	t.Parallel()
+
+	ms := Measurements{
+		ConcurrencyAndThroughput(3, 30),
+		ConcurrencyAndThroughput(1, 10),
+		ConcurrencyAndThroughput(2, 20),
+		ConcurrencyAndThroughput(1, 11),
+	}
+	ms.Sort()
+
+	assert.Equal(t, "Measurements", Measurements{
+		ConcurrencyAndThroughput(1, 10),
+		ConcurrencyAndThroughput(1, 11),
+		ConcurrencyAndThroughput(2, 20),
+		ConcurrencyAndThroughput(3, 30),
+	}, ms)
+}
+
+func TestMeasurements_Finite(t *testing.T) {
+	t.Parallel()
+
+	ms := Measurements{
+		ConcurrencyAndThroughput(1, 10),
+		ConcurrencyAndThroughput(2, math.NaN()),
+		ConcurrencyAndThroughput(0, 0),
+		ConcurrencyAndThroughput(3, math.Inf(1)),
+		ConcurrencyAndThroughput(4, 40),
+	}
+
+	assert.Equal(t, "Measurements", Measurements{
+		ConcurrencyAndThroughput(1, 10),
+		ConcurrencyAndThroughput(4, 40),
+	}, ms.Finite())
+}
+
+func TestMeasurements_Summarize(t *testing.T) {
+	t.Parallel()
+
+	ms := Measurements{
+		{Concurrency: 2, Throughput: 20, Latency: 0.1},
+		{Concurrency: 1, Throughput: 10, Latency: 0.1},
+		{Concurrency: 2, Throughput: 24, Latency: 0.3},
+		{Concurrency: 2, Throughput: 22, Latency: 0.8},
+	}
+
+	assert.Equal(t, "Summaries", []Summary{
+		{
+			Concurrency: 1,
+			Count:       1,
+			Mean:        Measurement{Concurrency: 1, Throughput: 10, Latency: 0.1, Samples: 1},
+			Median:      Measurement{Concurrency: 1, Throughput: 10, Latency: 0.1},
+		},
+		{
+			Concurrency: 2,
+			Count:       3,
+			Mean: Measurement{
+				Concurrency:      2,
+				Throughput:       22,
+				Latency:          0.4,
+				ThroughputStdDev: 2,
+				LatencyStdDev:    0.36055512754639896,
+				Samples:          3,
+			},
+			Median:             Measurement{Concurrency: 2, Throughput: 22, Latency: 0.3},
+			ThroughputVariance: 4,
+			LatencyVariance:    0.13,
+		},
+	}, ms.Summarize(), epsilon)
+}
+
+func TestMeasurements_Merge(t *testing.T) {
+	t.Parallel()
+
+	ms := Measurements{
+		{Concurrency: 1, Throughput: 10, Latency: 0.1},
+		{Concurrency: 1, Throughput: 12, Latency: 0.1},
+		{Concurrency: 1, Throughput: 20, Latency: 0.4},
+		{Concurrency: 1, Throughput: 30, Latency: 0.2},
+	}
+
+	assert.Equal(t, "Mean", Measurements{
+		{
+			Concurrency:      1,
+			Throughput:       18,
+			Latency:          0.2,
+			ThroughputStdDev: 9.092121131323903,
+			LatencyStdDev:    0.14142135623730953,
+			Samples:          4,
+		},
+	}, ms.Merge(AggregateMean), epsilon)
+
+	assert.Equal(t, "Median", Measurements{
+		{Concurrency: 1, Throughput: 16, Latency: 0.15},
+	}, ms.Merge(AggregateMedian), epsilon)
+}
+
+func TestMeasurements_CSV(t *testing.T) {
+	t.Parallel()
+
+	want := Measurements(measurements)
+
+	buf := new(bytes.Buffer)
+	if err := want.WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadCSV(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Measurements", want, got)
+}
+
+func TestReadCSV_Derived(t *testing.T) {
+	t.Parallel()
+
+	got, err := ReadCSV(strings.NewReader("latency,host,throughput\n0.6,a,5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Measurements", Measurements{
+		ThroughputAndLatency(5, 600*time.Millisecond),
+	}, got, epsilon)
+}
+
+func TestReadCSV_MissingColumns(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadCSV(strings.NewReader("concurrency,host\n1,a\n"))
+	if !errors.Is(err, ErrMissingColumns) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMeasurements_JSON(t *testing.T) {
+	t.Parallel()
+
+	want := Measurements{{Concurrency: 1, Throughput: 2, Latency: 0.5}}
+
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "JSON", `[{"concurrency":1,"throughput":2,"latency":0.5}]`, string(data))
+
+	var got Measurements
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Measurements", want, got)
+}
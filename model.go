@@ -3,6 +3,9 @@
 package usl
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 
@@ -21,6 +24,50 @@ func (m *Model) String() string {
 	return fmt.Sprintf("Model{σ=%v,κ=%v,λ=%v}", m.Sigma, m.Kappa, m.Lambda)
 }
 
+// MarshalText returns the model in the same form as String.
+func (m Model) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText parses a model in the form returned by String.
+func (m *Model) UnmarshalText(text []byte) error {
+	var sigma, kappa, lambda float64
+
+	// Scanning stops after the closing brace, so check that nothing follows it.
+	r := bytes.NewReader(text)
+	if _, err := fmt.Fscanf(r, "Model{σ=%g,κ=%g,λ=%g}", &sigma, &kappa, &lambda); err != nil || r.Len() > 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidModel, text)
+	}
+
+	m.Sigma, m.Kappa, m.Lambda = sigma, kappa, lambda
+
+	return nil
+}
+
+// jsonModel is the JSON representation of a model.
+type jsonModel struct {
+	Sigma  float64 `json:"sigma"`
+	Kappa  float64 `json:"kappa"`
+	Lambda float64 `json:"lambda"`
+}
+
+// MarshalJSON returns the model as a JSON object with sigma, kappa, and lambda fields.
+func (m Model) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonModel(m))
+}
+
+// UnmarshalJSON parses a model from a JSON object with sigma, kappa, and lambda fields.
+func (m *Model) UnmarshalJSON(data []byte) error {
+	var j jsonModel
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	*m = Model(j)
+
+	return nil
+}
+
 // ThroughputAtConcurrency returns the expected throughput given a number of concurrent events,
 // X(N).
 //
@@ -82,6 +129,104 @@ func (m *Model) ConcurrencyAtThroughput(x float64) float64 {
 	return m.LatencyAtThroughput(x) * x
 }
 
+// RelativeCapacity returns the expected throughput at the given number of concurrent events
+// relative to the throughput of a single event, C(N)=X(N)/X(1).
+//
+// See "Practical Scalability Analysis with the Universal Scalability Law, Equation 2".
+func (m *Model) RelativeCapacity(n float64) float64 {
+	return n / (1 + (m.Sigma * (n - 1)) + (m.Kappa * n * (n - 1)))
+}
+
+// Efficiency returns the relative capacity per unit of concurrency, C(N)/N. A linearly scalable
+// system has an efficiency of 1 at all levels of concurrency.
+func (m *Model) Efficiency(n float64) float64 {
+	return m.RelativeCapacity(n) / n
+}
+
+// MarginalThroughput returns the expected change in throughput from adding one more unit of
+// concurrency to the given number of concurrent events, X(N+1)-X(N). It becomes negative beyond
+// MaxConcurrency.
+func (m *Model) MarginalThroughput(n float64) float64 {
+	return m.ThroughputAtConcurrency(n+1) - m.ThroughputAtConcurrency(n)
+}
+
+// KneeByEfficiency returns the largest integral number of concurrent events at which the system's
+// efficiency is at least e (e.g. 0.8 for 80% of linear scalability), capped at MaxConcurrency.
+//
+// Returns +Inf for a system with neither contention nor coherency costs, since its efficiency
+// never drops. Returns ErrInvalidThreshold if e is not in (0,1], and ErrUnbounded if the model has
+// no peak (e.g. κ<0).
+func (m *Model) KneeByEfficiency(e float64) (float64, error) {
+	if !(e > 0 && e <= 1) {
+		return 0, fmt.Errorf("%w: efficiency %v", ErrInvalidThreshold, e)
+	}
+
+	if m.Sigma == 0 && m.Kappa == 0 {
+		return math.Inf(1), nil
+	}
+
+	peak, err := m.peak()
+	if err != nil {
+		return 0, err
+	}
+
+	// C(N)/N ≥ e is equivalent to σ(N-1) + κN(N-1) ≤ 1/e - 1.
+	d := 1/e - 1
+
+	var n float64
+
+	if m.Kappa != 0 {
+		// Solve κN² + (σ-κ)N - (σ+d) = 0 for its positive root.
+		b := m.Sigma - m.Kappa
+		n = (-b + math.Sqrt(b*b+4*m.Kappa*(m.Sigma+d))) / (2 * m.Kappa)
+	} else {
+		n = d/m.Sigma + 1
+	}
+
+	return math.Max(1, math.Min(math.Floor(n), peak)), nil
+}
+
+// KneeByMarginalThroughput returns the largest integral number of concurrent events at which adding
+// the last unit of concurrency increased throughput by at least g times the throughput of a single
+// event (e.g. 0.5 for half of X(1)), capped at MaxConcurrency.
+//
+// Returns +Inf for a system whose marginal throughput never drops below g: one with neither
+// contention nor coherency costs, or one with no coherency costs if g is zero. Returns
+// ErrInvalidThreshold if g is negative, and ErrUnbounded if the model has no peak (e.g. κ<0).
+func (m *Model) KneeByMarginalThroughput(g float64) (float64, error) {
+	if !(g >= 0) {
+		return 0, fmt.Errorf("%w: marginal throughput %v", ErrInvalidThreshold, g)
+	}
+
+	// With no coherency costs, marginal throughput falls towards zero but never reaches it.
+	if (m.Sigma == 0 && m.Kappa == 0) || (m.Kappa == 0 && g == 0) {
+		return math.Inf(1), nil
+	}
+
+	peak, err := m.peak()
+	if err != nil {
+		return 0, err
+	}
+
+	x1 := m.ThroughputAtConcurrency(1)
+
+	n := lastSatisfying(func(n float64) bool {
+		return m.MarginalThroughput(n-1) >= g*x1
+	})
+
+	return math.Min(n, peak), nil
+}
+
+// peak returns the model's MaxConcurrency, or ErrUnbounded if it has none.
+func (m *Model) peak() (float64, error) {
+	n := m.MaxConcurrency()
+	if m.Kappa < 0 || math.IsNaN(n) || n <= 0 {
+		return 0, ErrUnbounded
+	}
+
+	return n, nil
+}
+
 // ContentionConstrained returns true if the system is constrained by contention.
 func (m *Model) ContentionConstrained() bool {
 	return m.Sigma > m.Kappa
@@ -102,26 +247,49 @@ func (m *Model) Limitless() bool {
 // Finds a set of coefficients for the equation y = λx/(1+σ(x-1)+κx(x-1)) which best fit the
 // observed values using unconstrained least-squares regression. The resulting values for λ, κ, and
 // σ are the parameters of the returned model.
+//
+// If every measurement has a throughput standard deviation, each is weighted by the inverse of the
+// standard error of its throughput, so that more precise measurements have more influence on the
+// model.
 func Build(measurements []Measurement) (m *Model, err error) {
 	if len(measurements) < minMeasurements {
 		return nil, ErrInsufficientMeasurements
 	}
 
+	return buildFrom(measurements, initialGuess(measurements), coldTau)
+}
+
+// initialGuess returns an initial guess at the parameters of a model of the given measurements.
+func initialGuess(measurements []Measurement) *Model {
 	// Calculate x/n for all measurements.
 	xn := make([]float64, len(measurements))
 	for i, m := range measurements {
 		xn[i] = m.Throughput / m.Concurrency
 	}
 
-	// Calculate an initial guess at the model parameters.
-	init := []float64{0.1, 0.01, floats.Max(xn)}
+	return &Model{Sigma: 0.1, Kappa: 0.01, Lambda: floats.Max(xn)}
+}
+
+// buildFrom fits a model to the given measurements, starting from the parameters of the given
+// model and using the given initial damping factor.
+func buildFrom(measurements []Measurement, guess *Model, tau float64) (*Model, error) {
+	// Fit λ in units of the initial guess, so that it's on the same scale as σ and κ. Otherwise, for
+	// systems with high throughput, steps in σ and κ are negligible relative to the size of the
+	// parameter vector and the solver stops before it has converged.
+	scale := guess.Lambda
+	if scale <= 0 || math.IsInf(scale, 0) || math.IsNaN(scale) {
+		scale = 1
+	}
+
+	init := []float64{guess.Sigma, guess.Kappa, guess.Lambda / scale}
 
-	// Calculate the residuals of a possible model.
+	// Calculate the weighted residuals of a possible model, in the same units.
+	w := weights(measurements)
 	f := func(dst, x []float64) {
-		model := Model{Sigma: x[0], Kappa: x[1], Lambda: x[2]}
+		model := Model{Sigma: x[0], Kappa: x[1], Lambda: x[2] * scale}
 
 		for i, v := range measurements {
-			dst[i] = v.Throughput - model.ThroughputAtConcurrency(v.Concurrency)
+			dst[i] = w[i] * (v.Throughput - model.ThroughputAtConcurrency(v.Concurrency)) / scale
 		}
 	}
 	j := lm.NumJac{Func: f}
@@ -133,7 +301,7 @@ func Build(measurements []Measurement) (m *Model, err error) {
 		Func:       f,                 // Reduce the residuals of model predictions to observations.
 		Jac:        j.Jac,             // Approximate the Jacobian by finite differences.
 		InitParams: init,              // Use our initial guesses at parameters.
-		Tau:        1e-6,              // Need a non-zero initial damping factor.
+		Tau:        tau,               // Need a non-zero initial damping factor.
 		Eps1:       1e-8,              // Small but non-zero values here prevent singular matrices.
 		Eps2:       1e-8,
 	}
@@ -148,15 +316,57 @@ func Build(measurements []Measurement) (m *Model, err error) {
 	return &Model{
 		Sigma:  results.X[0],
 		Kappa:  results.X[1],
-		Lambda: results.X[2],
+		Lambda: results.X[2] * scale,
 	}, nil
 }
 
+// weights returns the weight of each measurement's residual: the inverse of the standard error of
+// its throughput if every measurement has one, or 1 otherwise.
+func weights(measurements []Measurement) []float64 {
+	w := make([]float64, len(measurements))
+
+	for i, m := range measurements {
+		if m.ThroughputStdDev <= 0 {
+			for j := range w {
+				w[j] = 1
+			}
+
+			return w
+		}
+
+		stdErr := m.ThroughputStdDev
+		if m.Samples > 1 {
+			stdErr /= math.Sqrt(float64(m.Samples))
+		}
+
+		w[i] = 1 / stdErr
+	}
+
+	return w
+}
+
 const (
 	// minMeasurement is the smallest number of measurements from which a useful model can be
 	// created.
 	minMeasurements = 6
+
+	// coldTau is the initial damping factor used when starting from a rough guess.
+	coldTau = 1e-6
+
+	// warmTau is the initial damping factor used when starting from a previous model's parameters.
+	// Close to the solution, lightly-damped Gauss-Newton steps converge quickly, whereas the heavy
+	// damping of coldTau leaves λ almost unchanged and stops the solver early.
+	warmTau = 1e-12
 )
 
-// ErrInsufficientMeasurements is returned when fewer than 6 measurements were provided.
-var ErrInsufficientMeasurements = fmt.Errorf("usl: need at least %d measurements", minMeasurements)
+var (
+	// ErrInsufficientMeasurements is returned when fewer than 6 measurements were provided.
+	ErrInsufficientMeasurements = fmt.Errorf("usl: need at least %d measurements", minMeasurements)
+
+	// ErrInvalidThreshold is returned when a knee's efficiency or marginal throughput threshold is
+	// out of range.
+	ErrInvalidThreshold = errors.New("usl: invalid knee threshold")
+
+	// ErrInvalidModel is returned when a model cannot be parsed or has parameters which aren't finite.
+	ErrInvalidModel = errors.New("usl: invalid model")
+)
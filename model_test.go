@@ -1,6 +1,9 @@
 package usl
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
 	"testing"
 
 	"github.com/codahale/gubbins/assert"
@@ -8,7 +11,10 @@ import (
 )
 
 //nolint:gochecknoglobals // fine in tests
-var epsilon = cmpopts.EquateApprox(0.00001, 0)
+var (
+	epsilon      = cmpopts.EquateApprox(0.00001, 0)
+	epsilonLoose = cmpopts.EquateApprox(0.001, 0)
+)
 
 func TestModel_Kappa(t *testing.T) {
 	t.Parallel()
@@ -129,6 +135,147 @@ func TestModel_ConcurrencyAtLatency(t *testing.T) {
 	assert.Equal(t, "N(R=0.0020)", 29.88889360938781, m.ConcurrencyAtLatency(0.0020), epsilon)
 }
 
+func TestModel_RelativeCapacity(t *testing.T) {
+	t.Parallel()
+
+	m := &Model{Sigma: 0.06, Kappa: 0.06, Lambda: 40}
+
+	assert.Equal(t, "C(N=1)", 1.0, m.RelativeCapacity(1), epsilon)
+	assert.Equal(t, "C(N=2)", 1.6949152542372883, m.RelativeCapacity(2), epsilon)
+	assert.Equal(t, "C(N=4)",
+		m.ThroughputAtConcurrency(4)/m.ThroughputAtConcurrency(1), m.RelativeCapacity(4), epsilon)
+}
+
+func TestModel_Efficiency(t *testing.T) {
+	t.Parallel()
+
+	m := &Model{Sigma: 0.06, Kappa: 0.06, Lambda: 40}
+
+	assert.Equal(t, "E(N=1)", 1.0, m.Efficiency(1), epsilon)
+	assert.Equal(t, "E(N=2)", 0.8474576271186441, m.Efficiency(2), epsilon)
+
+	m = &Model{Sigma: 0, Kappa: 0, Lambda: 40}
+
+	assert.Equal(t, "E(N=100)", 1.0, m.Efficiency(100), epsilon)
+}
+
+func TestModel_MarginalThroughput(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	assert.Equal(t, "dX(N=1)",
+		m.ThroughputAtConcurrency(2)-m.ThroughputAtConcurrency(1), m.MarginalThroughput(1), epsilon)
+
+	if m.MarginalThroughput(m.MaxConcurrency()+1) >= 0 {
+		t.Error("marginal throughput should be negative beyond the peak")
+	}
+}
+
+func TestModel_KneeByEfficiency(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	n, err := m.KneeByEfficiency(0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Knee", 8.0, n, epsilon)
+
+	if m.Efficiency(n) < 0.8 || m.Efficiency(n+1) >= 0.8 {
+		t.Errorf("E(N=%v)=%v, E(N=%v)=%v", n, m.Efficiency(n), n+1, m.Efficiency(n+1))
+	}
+
+	assert.Equal(t, "Knee(E=0.01)", m.MaxConcurrency(), knee(t, m.KneeByEfficiency, 0.01), epsilon)
+
+	m = &Model{Sigma: 0.1, Kappa: 0, Lambda: 40}
+
+	assert.Equal(t, "Knee(κ=0)", 3.0, knee(t, m.KneeByEfficiency, 0.8), epsilon)
+
+	m = &Model{Sigma: 0, Kappa: 0, Lambda: 40}
+
+	assert.Equal(t, "Knee(σ=0,κ=0)", math.Inf(1), knee(t, m.KneeByEfficiency, 0.8))
+}
+
+func TestModel_KneeByEfficiency_Invalid(t *testing.T) {
+	t.Parallel()
+
+	m := &Model{Sigma: 0.02, Kappa: 0.0001, Lambda: 100}
+
+	for _, e := range []float64{0, -0.5, 1.5, math.NaN()} {
+		if _, err := m.KneeByEfficiency(e); !errors.Is(err, ErrInvalidThreshold) {
+			t.Errorf("KneeByEfficiency(%v) = %v, want %v", e, err, ErrInvalidThreshold)
+		}
+	}
+
+	m = &Model{Sigma: 0.02, Kappa: -0.0001, Lambda: 100}
+
+	if _, err := m.KneeByEfficiency(0.8); !errors.Is(err, ErrUnbounded) {
+		t.Errorf("KneeByEfficiency(κ<0) = %v, want %v", err, ErrUnbounded)
+	}
+}
+
+func TestModel_KneeByMarginalThroughput(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	n, err := m.KneeByMarginalThroughput(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Knee", 11.0, n, epsilon)
+
+	x1 := m.ThroughputAtConcurrency(1)
+	if m.MarginalThroughput(n-1) < 0.5*x1 || m.MarginalThroughput(n) >= 0.5*x1 {
+		t.Errorf("dX(N=%v)=%v, dX(N=%v)=%v", n-1, m.MarginalThroughput(n-1), n, m.MarginalThroughput(n))
+	}
+
+	assert.Equal(t, "Knee(G=0)", m.MaxConcurrency(), knee(t, m.KneeByMarginalThroughput, 0), epsilon)
+
+	m = &Model{Sigma: 0.1, Kappa: 0, Lambda: 40}
+
+	assert.Equal(t, "Knee(κ=0)", 4.0, knee(t, m.KneeByMarginalThroughput, 0.5), epsilon)
+	assert.Equal(t, "Knee(κ=0,G=0)", math.Inf(1), knee(t, m.KneeByMarginalThroughput, 0))
+
+	m = &Model{Sigma: 0, Kappa: 0, Lambda: 40}
+
+	assert.Equal(t, "Knee(σ=0,κ=0)", math.Inf(1), knee(t, m.KneeByMarginalThroughput, 0.5))
+}
+
+func TestModel_KneeByMarginalThroughput_Invalid(t *testing.T) {
+	t.Parallel()
+
+	m := &Model{Sigma: 0.02, Kappa: 0.0001, Lambda: 100}
+
+	for _, g := range []float64{-0.5, math.NaN()} {
+		if _, err := m.KneeByMarginalThroughput(g); !errors.Is(err, ErrInvalidThreshold) {
+			t.Errorf("KneeByMarginalThroughput(%v) = %v, want %v", g, err, ErrInvalidThreshold)
+		}
+	}
+
+	m = &Model{Sigma: 0.02, Kappa: -0.0001, Lambda: 100}
+
+	if _, err := m.KneeByMarginalThroughput(0.5); !errors.Is(err, ErrUnbounded) {
+		t.Errorf("KneeByMarginalThroughput(κ<0) = %v, want %v", err, ErrUnbounded)
+	}
+}
+
+// knee returns the knee found with the given threshold, failing the test on an error.
+func knee(t *testing.T, f func(float64) (float64, error), threshold float64) float64 {
+	t.Helper()
+
+	n, err := f(threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
+
 func TestModel_Limitless(t *testing.T) {
 	t.Parallel()
 
@@ -149,6 +296,109 @@ func TestModel_String(t *testing.T) {
 	assert.Equal(t, "String", "Model{σ=1,κ=2,λ=3}", m.String())
 }
 
+func TestBuild_HighThroughput(t *testing.T) {
+	t.Parallel()
+
+	want := Model{Sigma: 0.03, Kappa: 0.0006, Lambda: 1e8}
+
+	measurements := make([]Measurement, 0, 7)
+	for _, n := range []uint64{1, 2, 4, 8, 16, 32, 64} {
+		measurements = append(measurements, ConcurrencyAndThroughput(n, want.ThroughputAtConcurrency(float64(n))))
+	}
+
+	m, err := Build(measurements)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Model", &want, m, epsilonLoose)
+}
+
+func TestBuild_Weighted(t *testing.T) {
+	t.Parallel()
+
+	// Equal weights make no difference.
+	weighted := make([]Measurement, len(measurements))
+	for i, m := range measurements {
+		m.ThroughputStdDev = 100
+		weighted[i] = m
+	}
+
+	m, err := Build(weighted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Model", build(t), m, epsilon)
+
+	// A very imprecise outlier has almost no influence.
+	weighted[len(weighted)-1].Throughput *= 2
+	weighted[len(weighted)-1].ThroughputStdDev = 1e9
+
+	m, err = Build(weighted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := Build(measurements[:len(measurements)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Model", want, m, cmpopts.EquateApprox(0.001, 0))
+}
+
+func TestModel_MarshalText(t *testing.T) {
+	t.Parallel()
+
+	want := Model{Sigma: 0.02671591, Kappa: 7.690945e-4, Lambda: 995.6486}
+
+	text, err := want.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Text", "Model{σ=0.02671591,κ=0.0007690945,λ=995.6486}", string(text))
+
+	var got Model
+	if err := got.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Model", want, got)
+}
+
+func TestModel_UnmarshalText_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{"Model{σ=1,κ=2}", "Model{σ=1,κ=2,λ=3}junk", "Model{σ=1,κ=2,λ=3} "} {
+		var m Model
+		if err := m.UnmarshalText([]byte(text)); !errors.Is(err, ErrInvalidModel) {
+			t.Errorf("%q: unexpected error: %v", text, err)
+		}
+	}
+}
+
+func TestModel_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	want := Model{Sigma: 0.02671591, Kappa: 7.690945e-4, Lambda: 995.6486}
+
+	data, err := json.Marshal(&want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "JSON", `{"sigma":0.02671591,"kappa":0.0007690945,"lambda":995.6486}`, string(data))
+
+	var got Model
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Model", want, got)
+}
+
 func BenchmarkBuild(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		build(b)
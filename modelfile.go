@@ -0,0 +1,74 @@
+package usl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ModelFileVersion is the version of the model file format written by WriteModelFile.
+const ModelFileVersion = 1
+
+// ModelFile is a persisted model along with the measurements it was built from and metadata
+// describing where it came from.
+type ModelFile struct {
+	Version      int               `json:"version"`          // The version of the file format.
+	Model        Model             `json:"model"`            // The model.
+	Fit          Fit               `json:"fit"`              // How well the model fits its measurements.
+	Measurements []Measurement     `json:"measurements"`     // The measurements used to build the model.
+	CreatedAt    time.Time         `json:"created_at"`       // When the model was built.
+	Tool         string            `json:"tool,omitempty"`   // The tool and version which built the model.
+	Labels       map[string]string `json:"labels,omitempty"` // Free-form labels (e.g. service, commit).
+}
+
+// NewModelFile builds a model from the given measurements and returns it as a model file with the
+// given tool and labels.
+func NewModelFile(measurements []Measurement, tool string, labels map[string]string) (*ModelFile, error) {
+	m, err := Build(measurements)
+	if err != nil {
+		return nil, err
+	}
+
+	fit, err := m.Fit(measurements)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ModelFile{
+		Version:      ModelFileVersion,
+		Model:        *m,
+		Fit:          fit,
+		Measurements: measurements,
+		CreatedAt:    time.Now().UTC(),
+		Tool:         tool,
+		Labels:       labels,
+	}, nil
+}
+
+// WriteModelFile writes the given model file to w as indented JSON.
+func WriteModelFile(w io.Writer, f *ModelFile) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	return e.Encode(f)
+}
+
+// ReadModelFile reads a model file from r. Returns ErrUnsupportedVersion if the file was written
+// in a format version this package does not understand.
+func ReadModelFile(r io.Reader) (*ModelFile, error) {
+	var f ModelFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("unable to read model file: %w", err)
+	}
+
+	if f.Version != ModelFileVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, f.Version)
+	}
+
+	return &f, nil
+}
+
+// ErrUnsupportedVersion is returned when a model file has an unknown format version.
+var ErrUnsupportedVersion = errors.New("usl: unsupported model file version")
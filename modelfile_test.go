@@ -0,0 +1,43 @@
+package usl
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+)
+
+func TestModelFile_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want, err := NewModelFile(measurements, "usl dev", map[string]string{"service": "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Version", ModelFileVersion, want.Version)
+	assert.Equal(t, "Model", *build(t), want.Model, epsilon)
+
+	buf := new(bytes.Buffer)
+	if err := WriteModelFile(buf, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadModelFile(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "ModelFile", want, got)
+}
+
+func TestReadModelFile_UnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadModelFile(strings.NewReader(`{"version":2}`))
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
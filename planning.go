@@ -0,0 +1,249 @@
+package usl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Capacity is the maximum load a system can sustain while keeping its mean latency at or below a
+// target.
+type Capacity struct {
+	Latency     float64 // The mean latency target, in seconds.
+	Concurrency float64 // The highest number of concurrent events which meets the target.
+	Throughput  float64 // The expected throughput at that concurrency, in events/sec.
+}
+
+// Headroom returns the fraction of the capacity's throughput which is unused at the given
+// throughput. A negative value indicates the system is already beyond its capacity.
+func (c Capacity) Headroom(x float64) float64 {
+	return 1 - x/c.Throughput
+}
+
+// CapacityAtLatency returns the highest throughput and concurrency the system can run at while its
+// mean latency stays at or below the given target, in seconds.
+//
+// Latency rises monotonically with concurrency, but throughput peaks at Nmax and then falls. The
+// returned concurrency is therefore never greater than MaxConcurrency, even if the latency target
+// would allow it: adding load beyond the peak only reduces throughput. If the model has no peak
+// because κ=0, the returned concurrency is limited only by the latency target. If it has no peak
+// because κ<0 or σ≥1, ErrUnbounded is returned.
+func (m *Model) CapacityAtLatency(r float64) (Capacity, error) {
+	peak := m.MaxConcurrency()
+	if math.IsNaN(peak) || peak <= 0 {
+		return Capacity{}, ErrUnbounded
+	}
+
+	if r < m.LatencyAtConcurrency(1) {
+		return Capacity{}, ErrUnattainableLatency
+	}
+
+	n := math.Min(m.concurrencyAtLatency(r), peak)
+
+	return Capacity{
+		Latency:     r,
+		Concurrency: n,
+		Throughput:  m.ThroughputAtConcurrency(n),
+	}, nil
+}
+
+// Plan is the smallest integral concurrency (e.g. number of nodes in a cluster) at which a system
+// is expected to reach a throughput target.
+type Plan struct {
+	Concurrency uint64  // The smallest number of concurrent units which meets the target.
+	Throughput  float64 // The expected throughput at that concurrency, in events/sec.
+	Latency     float64 // The expected mean latency at that concurrency, in seconds.
+}
+
+// PlanForThroughput returns the smallest integral concurrency at which the system's expected
+// throughput is at least x. If r is greater than zero, the expected mean latency at that
+// concurrency must also be at or below r, in seconds.
+//
+// Returns ErrUnattainableThroughput if x is greater than the model's maximum throughput, and
+// ErrUnattainableLatency if the latency ceiling cannot be met at the required concurrency. Because
+// latency only rises with concurrency, no larger concurrency would meet the ceiling either.
+func (m *Model) PlanForThroughput(x, r float64) (Plan, error) {
+	if x > m.MaxThroughput() {
+		return Plan{}, ErrUnattainableThroughput
+	}
+
+	// Start with the real-valued solution and correct for any rounding error.
+	n := m.concurrencyAtThroughput(x)
+	if math.IsNaN(n) || math.IsInf(n, 0) || n < 0 {
+		return Plan{}, ErrUnattainableThroughput
+	}
+
+	n = math.Max(1, math.Ceil(n))
+	for n > 1 && m.ThroughputAtConcurrency(n-1) >= x {
+		n--
+	}
+
+	for m.ThroughputAtConcurrency(n) < x {
+		n++
+	}
+
+	p := m.plan(n)
+	if r > 0 && p.Latency > r {
+		return Plan{}, ErrUnattainableLatency
+	}
+
+	return p, nil
+}
+
+func (m *Model) plan(n float64) Plan {
+	return Plan{
+		Concurrency: uint64(n),
+		Throughput:  m.ThroughputAtConcurrency(n),
+		Latency:     m.LatencyAtConcurrency(n),
+	}
+}
+
+// Cost is a linear model of the cost of running a system at a given level of concurrency.
+type Cost struct {
+	Fixed   float64 // The cost which does not depend on concurrency (e.g. a load balancer).
+	PerUnit float64 // The cost of each unit of concurrency (e.g. per node or per core).
+}
+
+// At returns the cost of running at the given concurrency.
+func (c Cost) At(n float64) float64 {
+	return c.Fixed + c.PerUnit*n
+}
+
+// validate returns ErrInvalidCost unless each unit of concurrency has a positive cost and the fixed
+// cost is not negative.
+func (c Cost) validate() error {
+	if c.PerUnit <= 0 || c.Fixed < 0 {
+		return fmt.Errorf("%w: fixed=%v, per unit=%v", ErrInvalidCost, c.Fixed, c.PerUnit)
+	}
+
+	return nil
+}
+
+// CostPlan is a Plan with its associated cost.
+type CostPlan struct {
+	Plan
+
+	Cost              float64 // The cost of running at the plan's concurrency.
+	ThroughputPerCost float64 // The plan's throughput divided by its cost.
+}
+
+// MostCostEffective returns the integral concurrency which maximizes throughput per unit of cost.
+//
+// Returns ErrInvalidCost if the cost per unit of concurrency is not positive or the fixed cost is
+// negative, and ErrUnbounded if the model has neither contention nor coherency costs, in which case
+// adding concurrency never reduces cost effectiveness.
+func (m *Model) MostCostEffective(c Cost) (CostPlan, error) {
+	if err := c.validate(); err != nil {
+		return CostPlan{}, err
+	}
+
+	if m.Sigma == 0 && m.Kappa == 0 {
+		return CostPlan{}, ErrUnbounded
+	}
+
+	// Throughput per cost rises to a single maximum and then falls, so find the last unit of
+	// concurrency which improved it.
+	effectiveness := func(n float64) float64 {
+		return m.ThroughputAtConcurrency(n) / c.At(n)
+	}
+
+	n := lastSatisfying(func(n float64) bool {
+		return effectiveness(n) > effectiveness(n-1)
+	})
+
+	return m.costPlan(c, n), nil
+}
+
+// CheapestForThroughput returns the lowest-cost plan which meets the throughput target x and, if r
+// is greater than zero, the mean latency ceiling r. See PlanForThroughput.
+//
+// Returns ErrInvalidCost if the cost per unit of concurrency is not positive or the fixed cost is
+// negative.
+func (m *Model) CheapestForThroughput(c Cost, x, r float64) (CostPlan, error) {
+	if err := c.validate(); err != nil {
+		return CostPlan{}, err
+	}
+
+	// Cost rises with concurrency, so the smallest concurrency is also the cheapest.
+	p, err := m.PlanForThroughput(x, r)
+	if err != nil {
+		return CostPlan{}, err
+	}
+
+	return m.costPlan(c, float64(p.Concurrency)), nil
+}
+
+func (m *Model) costPlan(c Cost, n float64) CostPlan {
+	p := CostPlan{
+		Plan: m.plan(n),
+		Cost: c.At(n),
+	}
+	p.ThroughputPerCost = p.Throughput / p.Cost
+
+	return p
+}
+
+// lastSatisfying returns the largest integer n≥1 for which f(n) is true, given that f is true for
+// every integer between 1 and n and false for every integer after it.
+func lastSatisfying(f func(n float64) bool) float64 {
+	lo, hi := 1.0, 2.0
+	for f(hi) {
+		lo, hi = hi, hi*2
+	}
+
+	for hi-lo > 1 {
+		if mid := math.Floor((lo + hi) / 2); f(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo
+}
+
+// concurrencyAtThroughput returns the smallest real N for which X(N)=x, which lies on the rising
+// side of the throughput curve.
+func (m *Model) concurrencyAtThroughput(x float64) float64 {
+	// λN = x(1+σ(N-1)+κN(N-1)) is a quadratic in N: xκN² + (xσ-xκ-λ)N + x(1-σ) = 0.
+	a := x * m.Kappa
+	b := x*m.Sigma - x*m.Kappa - m.Lambda
+	c := x * (1 - m.Sigma)
+
+	if a == 0 {
+		return -c / b
+	}
+
+	return (-b - math.Sqrt(b*b-4*a*c)) / (2 * a)
+}
+
+// concurrencyAtLatency returns N(R), handling the case of a linearly scalable system.
+func (m *Model) concurrencyAtLatency(r float64) float64 {
+	if m.Kappa != 0 {
+		return m.ConcurrencyAtLatency(r)
+	}
+
+	// With κ=0, R(N) is linear in N. With σ=0 too, latency never rises.
+	if m.Sigma == 0 {
+		return math.Inf(1)
+	}
+
+	return (m.Lambda*r-1)/m.Sigma + 1
+}
+
+var (
+	// ErrUnattainableLatency is returned when a latency target cannot be met.
+	ErrUnattainableLatency = errors.New("usl: latency target is unattainable")
+
+	// ErrUnattainableThroughput is returned when a throughput target is greater than the model's
+	// maximum throughput.
+	ErrUnattainableThroughput = errors.New("usl: throughput target is greater than max throughput")
+
+	// ErrUnbounded is returned when a model has no optimal concurrency (e.g. because it has no
+	// contention or coherency costs, or negative coherency costs).
+	ErrUnbounded = errors.New("usl: model is unbounded")
+
+	// ErrInvalidCost is returned when a cost model has a non-positive cost per unit of concurrency
+	// or a negative fixed cost.
+	ErrInvalidCost = errors.New("usl: invalid cost")
+)
@@ -0,0 +1,232 @@
+package usl
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+)
+
+func TestModel_CapacityAtLatency(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	c, err := m.CapacityAtLatency(0.0016)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Latency", 0.0016, c.Latency, epsilon)
+	assert.Equal(t, "Concurrency", m.ConcurrencyAtLatency(0.0016), c.Concurrency, epsilon)
+	assert.Equal(t, "Throughput", m.ThroughputAtConcurrency(c.Concurrency), c.Throughput, epsilon)
+}
+
+func TestModel_CapacityAtLatency_BeyondPeak(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	c, err := m.CapacityAtLatency(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Concurrency", m.MaxConcurrency(), c.Concurrency, epsilon)
+	assert.Equal(t, "Throughput", m.MaxThroughput(), c.Throughput, epsilon)
+}
+
+func TestModel_CapacityAtLatency_Unattainable(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	if _, err := m.CapacityAtLatency(0.0001); !errors.Is(err, ErrUnattainableLatency) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestModel_CapacityAtLatency_Limitless(t *testing.T) {
+	t.Parallel()
+
+	m := &Model{Sigma: 0.5, Kappa: 0, Lambda: 10}
+
+	c, err := m.CapacityAtLatency(0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Concurrency", 3.0, c.Concurrency, epsilon)
+	assert.Equal(t, "Latency", 0.2, m.LatencyAtConcurrency(c.Concurrency), epsilon)
+
+	m = &Model{Sigma: 0, Kappa: 0, Lambda: 10}
+
+	c, err = m.CapacityAtLatency(0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Concurrency", math.Inf(1), c.Concurrency)
+}
+
+func TestModel_CapacityAtLatency_NoPeak(t *testing.T) {
+	t.Parallel()
+
+	for _, m := range []*Model{
+		{Sigma: 0.02, Kappa: -0.0001, Lambda: 100},
+		{Sigma: 1.5, Kappa: 0.0001, Lambda: 100},
+	} {
+		if _, err := m.CapacityAtLatency(1); !errors.Is(err, ErrUnbounded) {
+			t.Errorf("%v.CapacityAtLatency() = %v, want %v", m, err, ErrUnbounded)
+		}
+	}
+}
+
+func TestCapacity_Headroom(t *testing.T) {
+	t.Parallel()
+
+	c := Capacity{Latency: 0.1, Concurrency: 10, Throughput: 100}
+
+	assert.Equal(t, "Headroom(X=25)", 0.75, c.Headroom(25), epsilon)
+	assert.Equal(t, "Headroom(X=150)", -0.5, c.Headroom(150), epsilon)
+}
+
+func TestModel_PlanForThroughput(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	p, err := m.PlanForThroughput(10000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Concurrency", uint64(16), p.Concurrency)
+	assert.Equal(t, "Throughput", m.ThroughputAtConcurrency(16), p.Throughput, epsilon)
+	assert.Equal(t, "Latency", m.LatencyAtConcurrency(16), p.Latency, epsilon)
+
+	if m.ThroughputAtConcurrency(15) >= 10000 {
+		t.Error("plan is not minimal")
+	}
+}
+
+func TestModel_PlanForThroughput_LatencyCeiling(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	if _, err := m.PlanForThroughput(10000, 0.002); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.PlanForThroughput(10000, 0.001); !errors.Is(err, ErrUnattainableLatency) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestModel_PlanForThroughput_Unattainable(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	if _, err := m.PlanForThroughput(20000, 0); !errors.Is(err, ErrUnattainableThroughput) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	m = &Model{Sigma: 0.5, Kappa: 0, Lambda: 10}
+
+	if _, err := m.PlanForThroughput(20, 0); !errors.Is(err, ErrUnattainableThroughput) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	p, err := m.PlanForThroughput(15, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Concurrency", uint64(3), p.Concurrency)
+}
+
+func TestCost_At(t *testing.T) {
+	t.Parallel()
+
+	c := Cost{Fixed: 100, PerUnit: 10}
+
+	assert.Equal(t, "At(N=5)", 150.0, c.At(5), epsilon)
+}
+
+func TestModel_MostCostEffective(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+	c := Cost{Fixed: 1000, PerUnit: 100}
+
+	p, err := m.MostCostEffective(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	best := 0.0
+
+	for n := 1.0; n <= m.MaxConcurrency(); n++ {
+		best = math.Max(best, m.ThroughputAtConcurrency(n)/c.At(n))
+	}
+
+	assert.Equal(t, "ThroughputPerCost", best, p.ThroughputPerCost, epsilon)
+	assert.Equal(t, "Cost", c.At(float64(p.Concurrency)), p.Cost, epsilon)
+}
+
+func TestModel_MostCostEffective_NoFixedCost(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	p, err := m.MostCostEffective(Cost{PerUnit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Concurrency", uint64(1), p.Concurrency)
+}
+
+func TestModel_MostCostEffective_Unbounded(t *testing.T) {
+	t.Parallel()
+
+	m := &Model{Sigma: 0, Kappa: 0, Lambda: 10}
+
+	if _, err := m.MostCostEffective(Cost{Fixed: 10, PerUnit: 1}); !errors.Is(err, ErrUnbounded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestModel_MostCostEffective_InvalidCost(t *testing.T) {
+	t.Parallel()
+
+	m := &Model{Sigma: 0.1, Kappa: 0, Lambda: 40}
+
+	for _, c := range []Cost{{Fixed: 10}, {}, {Fixed: -1, PerUnit: 1}, {Fixed: 10, PerUnit: -1}} {
+		if _, err := m.MostCostEffective(c); !errors.Is(err, ErrInvalidCost) {
+			t.Errorf("%+v: unexpected error: %v", c, err)
+		}
+
+		if _, err := m.CheapestForThroughput(c, 100, 0); !errors.Is(err, ErrInvalidCost) {
+			t.Errorf("%+v: unexpected error: %v", c, err)
+		}
+	}
+}
+
+func TestModel_CheapestForThroughput(t *testing.T) {
+	t.Parallel()
+
+	m := build(t)
+
+	p, err := m.CheapestForThroughput(Cost{Fixed: 1000, PerUnit: 100}, 10000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Concurrency", uint64(16), p.Concurrency)
+	assert.Equal(t, "Cost", 2600.0, p.Cost, epsilon)
+	assert.Equal(t, "ThroughputPerCost", p.Throughput/2600, p.ThroughputPerCost, epsilon)
+}
@@ -0,0 +1,262 @@
+// Package promusl exposes the parameters of USL models to Prometheus.
+//
+// Each model's σ, κ, λ, peak concurrency, peak throughput, and fit quality are written as gauges in
+// the Prometheus text exposition format or in OpenMetrics, with labels identifying the model. The
+// output is written directly, so no client library is required. A Handler serves the most recent
+// models on a metrics endpoint:
+//
+//     h := &promusl.Handler{}
+//     http.Handle("/metrics", h)
+//
+//     model, _, _ := tracker.Refit(time.Now())
+//     fit, _ := model.Fit(measurements)
+//     h.Set(promusl.Metrics{Model: *model, Fit: &fit, Labels: map[string]string{"service": "api"}})
+package promusl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/codahale/usl"
+)
+
+const (
+	// TextContentType is the content type of the Prometheus text exposition format.
+	TextContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	// OpenMetricsContentType is the content type of the OpenMetrics text format.
+	OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Metrics is a model to be exposed, along with how well it fits its measurements and the labels
+// which identify it (e.g. service, commit).
+type Metrics struct {
+	Model  usl.Model         // The model.
+	Fit    *usl.Fit          // How well the model fits its measurements, if known.
+	Labels map[string]string // The labels of the model's samples.
+}
+
+// WriteText writes the given models to w in the Prometheus text exposition format.
+func WriteText(w io.Writer, metrics ...Metrics) error {
+	return write(w, false, metrics)
+}
+
+// WriteOpenMetrics writes the given models to w in the OpenMetrics text format.
+func WriteOpenMetrics(w io.Writer, metrics ...Metrics) error {
+	return write(w, true, metrics)
+}
+
+// Handler is an http.Handler which serves the parameters of the most recently set models. It
+// writes OpenMetrics if the request accepts it and the Prometheus text exposition format
+// otherwise. Its methods are safe for concurrent use.
+type Handler struct {
+	mu      sync.RWMutex
+	metrics []Metrics
+}
+
+// Set replaces the models served by the handler.
+func (h *Handler) Set(metrics ...Metrics) {
+	metrics = append([]Metrics(nil), metrics...)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.metrics = metrics
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	metrics := h.metrics
+	h.mu.RUnlock()
+
+	openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+
+	// Write into a buffer first, so invalid labels can be reported with an error status.
+	var b strings.Builder
+	if err := write(&b, openMetrics, metrics); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if openMetrics {
+		w.Header().Set("Content-Type", OpenMetricsContentType)
+	} else {
+		w.Header().Set("Content-Type", TextContentType)
+	}
+
+	_, _ = io.WriteString(w, b.String())
+}
+
+var (
+	// ErrInvalidLabel is returned when a label name is not a valid Prometheus label name.
+	ErrInvalidLabel = errors.New("promusl: invalid label name")
+
+	// ErrDuplicateLabels is returned when more than one model has the same labels, which would
+	// result in duplicate samples.
+	ErrDuplicateLabels = errors.New("promusl: duplicate label set")
+)
+
+// gauge is a family of gauges derived from a model.
+type gauge struct {
+	name, help string
+	value      func(m *Metrics) (float64, bool)
+}
+
+//nolint:gochecknoglobals // effectively constant
+var (
+	gauges = []gauge{
+		{"usl_sigma", "The contention coefficient of the model, σ.", modelValue(func(m *usl.Model) float64 {
+			return m.Sigma
+		})},
+		{"usl_kappa", "The coherency coefficient of the model, κ.", modelValue(func(m *usl.Model) float64 {
+			return m.Kappa
+		})},
+		{"usl_lambda", "The coefficient of performance of the model, λ: its throughput at a concurrency of one.",
+			modelValue(func(m *usl.Model) float64 {
+				return m.Lambda
+			})},
+		{"usl_peak_concurrency", "The concurrency at which the model predicts peak throughput.",
+			modelValue((*usl.Model).MaxConcurrency)},
+		{"usl_peak_throughput", "The peak throughput predicted by the model.",
+			modelValue((*usl.Model).MaxThroughput)},
+		{"usl_fit_r2", "The coefficient of determination of the model's fit, R².", fitValue(func(f *usl.Fit) float64 {
+			return f.R2
+		})},
+		{"usl_fit_rmse", "The root mean squared error of the model's fit.", fitValue(func(f *usl.Fit) float64 {
+			return f.RMSE
+		})},
+		{"usl_fit_degrees_of_freedom", "The number of measurements used to fit the model minus three.",
+			fitValue(func(f *usl.Fit) float64 {
+				return float64(f.DegreesOfFreedom)
+			})},
+		{"usl_fit_sigma_std_err", "The standard error of σ.", fitValue(func(f *usl.Fit) float64 {
+			return f.SigmaStdErr
+		})},
+		{"usl_fit_kappa_std_err", "The standard error of κ.", fitValue(func(f *usl.Fit) float64 {
+			return f.KappaStdErr
+		})},
+		{"usl_fit_lambda_std_err", "The standard error of λ.", fitValue(func(f *usl.Fit) float64 {
+			return f.LambdaStdErr
+		})},
+	}
+
+	labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	labelValue = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+// modelValue returns a gauge value of each model.
+func modelValue(f func(m *usl.Model) float64) func(m *Metrics) (float64, bool) {
+	return func(m *Metrics) (float64, bool) {
+		return f(&m.Model), true
+	}
+}
+
+// fitValue returns a gauge value of each model with a known fit.
+func fitValue(f func(fit *usl.Fit) float64) func(m *Metrics) (float64, bool) {
+	return func(m *Metrics) (float64, bool) {
+		if m.Fit == nil {
+			return 0, false
+		}
+
+		return f(m.Fit), true
+	}
+}
+
+// write writes the gauges of the given models to w, grouped by metric family.
+func write(w io.Writer, openMetrics bool, metrics []Metrics) error {
+	labels := make([]string, len(metrics))
+	seen := make(map[string]bool, len(metrics))
+
+	for i := range metrics {
+		s, err := formatLabels(metrics[i].Labels)
+		if err != nil {
+			return err
+		}
+
+		if seen[s] {
+			return fmt.Errorf("%w: %q", ErrDuplicateLabels, s)
+		}
+
+		seen[s] = true
+		labels[i] = s
+	}
+
+	bw := bufio.NewWriter(w)
+
+	for _, g := range gauges {
+		_, _ = fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+
+		for i := range metrics {
+			if v, ok := g.value(&metrics[i]); ok {
+				_, _ = fmt.Fprintf(bw, "%s%s %s\n", g.name, labels[i], formatValue(v))
+			}
+		}
+	}
+
+	if openMetrics {
+		_, _ = io.WriteString(bw, "# EOF\n")
+	}
+
+	return bw.Flush()
+}
+
+// formatLabels returns the given labels as a sorted, escaped label set, or an empty string if there
+// are none.
+func formatLabels(labels map[string]string) (string, error) {
+	if len(labels) == 0 {
+		return "", nil
+	}
+
+	names := make([]string, 0, len(labels))
+
+	for name := range labels {
+		if !labelName.MatchString(name) || strings.HasPrefix(name, "__") {
+			return "", fmt.Errorf("%w: %q", ErrInvalidLabel, name)
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var b strings.Builder
+
+	b.WriteByte('{')
+
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		_, _ = fmt.Fprintf(&b, `%s="%s"`, name, labelValue.Replace(labels[name]))
+	}
+
+	b.WriteByte('}')
+
+	return b.String(), nil
+}
+
+// formatValue returns v as a sample value.
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,183 @@
+package promusl
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+)
+
+//nolint:gochecknoglobals // test fixture
+var (
+	model = usl.Model{Sigma: 0.02, Kappa: 0.0001, Lambda: 1000}
+	fit   = usl.Fit{
+		R2:               0.99,
+		RMSE:             12.5,
+		DegreesOfFreedom: 5,
+		SigmaStdErr:      0.001,
+		KappaStdErr:      0.00001,
+		LambdaStdErr:     10,
+	}
+)
+
+func TestWriteText(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	if err := WriteText(&b,
+		Metrics{Model: model, Fit: &fit, Labels: map[string]string{"service": "api", "commit": "abc123"}},
+		Metrics{Model: model, Labels: map[string]string{"service": "a\"b\\c\nd"}},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+
+	assert.Equal(t, "head", []string{
+		"# HELP usl_sigma The contention coefficient of the model, σ.",
+		"# TYPE usl_sigma gauge",
+		`usl_sigma{commit="abc123",service="api"} 0.02`,
+		`usl_sigma{service="a\"b\\c\nd"} 0.02`,
+		"# HELP usl_kappa The coherency coefficient of the model, κ.",
+		"# TYPE usl_kappa gauge",
+		`usl_kappa{commit="abc123",service="api"} 0.0001`,
+		`usl_kappa{service="a\"b\\c\nd"} 0.0001`,
+	}, lines[:8])
+
+	assert.Equal(t, "lambda help", true, contains(lines,
+		"# HELP usl_lambda The coefficient of performance of the model, λ: its throughput at a concurrency of one."))
+	assert.Equal(t, "peak concurrency", true,
+		contains(lines, `usl_peak_concurrency{commit="abc123",service="api"} 98`))
+	assert.Equal(t, "fit", true,
+		contains(lines, `usl_fit_r2{commit="abc123",service="api"} 0.99`))
+	assert.Equal(t, "no fit", false,
+		contains(lines, `usl_fit_r2{service="a\"b\\c\nd"} 0.99`))
+	assert.Equal(t, "end", "", lines[len(lines)-1])
+	assert.Equal(t, "no EOF", false, contains(lines, "# EOF"))
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	if err := WriteOpenMetrics(&b, Metrics{Model: model}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "sigma", true, strings.HasPrefix(b.String(),
+		"# HELP usl_sigma The contention coefficient of the model, σ.\n# TYPE usl_sigma gauge\nusl_sigma 0.02\n"))
+	assert.Equal(t, "EOF", true, strings.HasSuffix(b.String(), "\n# EOF\n"))
+}
+
+func TestWriteText_InvalidLabel(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", "1service", "service-name", "__name__"} {
+		var b strings.Builder
+
+		err := WriteText(&b, Metrics{Model: model, Labels: map[string]string{name: "api"}})
+		if !errors.Is(err, ErrInvalidLabel) {
+			t.Errorf("label %q: error = %v, want ErrInvalidLabel", name, err)
+		}
+
+		assert.Equal(t, "output", "", b.String())
+	}
+}
+
+func TestWriteText_DuplicateLabels(t *testing.T) {
+	t.Parallel()
+
+	for _, labels := range [][2]map[string]string{
+		{nil, {}},
+		{{"service": "api", "commit": "abc"}, {"commit": "abc", "service": "api"}},
+	} {
+		var b strings.Builder
+
+		err := WriteText(&b,
+			Metrics{Model: model, Labels: labels[0]},
+			Metrics{Model: model, Labels: labels[1]})
+		if !errors.Is(err, ErrDuplicateLabels) {
+			t.Errorf("labels %v: error = %v, want ErrDuplicateLabels", labels, err)
+		}
+
+		assert.Equal(t, "output", "", b.String())
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "values", []string{"+Inf", "-Inf", "NaN", "1e+100", "-0.5"}, []string{
+		formatValue(math.Inf(1)),
+		formatValue(math.Inf(-1)),
+		formatValue(math.NaN()),
+		formatValue(1e100),
+		formatValue(-0.5),
+	})
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{}
+	h.Set(Metrics{Model: model, Labels: map[string]string{"service": "api"}})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, "status", http.StatusOK, w.Code)
+	assert.Equal(t, "content type", TextContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "sample", true, strings.Contains(w.Body.String(), "\n"+`usl_lambda{service="api"} 1000`+"\n"))
+	assert.Equal(t, "no EOF", false, strings.Contains(w.Body.String(), "# EOF"))
+
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	r.Header.Set("Accept", "application/openmetrics-text; version=1.0.0,text/plain;q=0.5")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, "OpenMetrics content type", OpenMetricsContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "EOF", true, strings.HasSuffix(w.Body.String(), "# EOF\n"))
+}
+
+func TestHandler_ServeHTTP_InvalidLabel(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{}
+	h.Set(Metrics{Model: model, Labels: map[string]string{"bad-label": "api"}})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, "status", http.StatusInternalServerError, w.Code)
+}
+
+func TestHandler_ServeHTTP_DuplicateLabels(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{}
+	h.Set(
+		Metrics{Model: model, Labels: map[string]string{"service": "api"}},
+		Metrics{Model: model, Labels: map[string]string{"service": "api"}},
+	)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, "status", http.StatusInternalServerError, w.Code)
+}
+
+func contains(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+
+	return false
+}
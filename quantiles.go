@@ -0,0 +1,90 @@
+package usl
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Quantile is the measured latency at a quantile of the latency distribution.
+type Quantile struct {
+	Quantile float64 `json:"quantile"` // The quantile, in the range [0,1] (e.g. 0.99 for p99).
+	Latency  float64 `json:"latency"`  // The latency at the quantile, in seconds.
+}
+
+// LatencyQuantiles are the latency quantiles of a system measured at a level of concurrency,
+// typically alongside a Measurement. Unlike a measurement's mean latency, quantiles are purely
+// empirical: Little's Law says nothing about them, and they are never inferred.
+//
+// Quantiles are kept apart from Measurement so that measurements remain comparable, and because
+// they can't be combined the way means can: the p99 of two sets of requests is not the mean of
+// their p99s.
+type LatencyQuantiles struct {
+	Concurrency float64    `json:"concurrency"` // The average number of concurrent events.
+	Quantiles   []Quantile `json:"quantiles"`   // The measured latency quantiles.
+}
+
+// LatencyAtQuantile returns the measured latency at the given quantile, if there is one.
+func (l *LatencyQuantiles) LatencyAtQuantile(q float64) (float64, bool) {
+	for _, v := range l.Quantiles {
+		if v.Quantile == q {
+			return v.Latency, true
+		}
+	}
+
+	return 0, false
+}
+
+// LatencyCurve is an empirical model of a latency quantile as a function of concurrency.
+//
+// Little's Law relates concurrency and throughput to mean latency only, so the USL says nothing
+// about the shape of the latency distribution. A LatencyCurve instead borrows the shape of the
+// USL's latency curve, R(N), which is a quadratic in N, and fits it directly to the observed
+// latencies at a quantile: R_q(N) = A + B·N + C·N². Its predictions are only as good as the
+// measurements behind it, and should not be extrapolated far beyond them.
+type LatencyCurve struct {
+	Quantile float64 // The quantile of latency being modeled (e.g. 0.99 for p99).
+	A        float64 // The constant coefficient.
+	B        float64 // The linear coefficient.
+	C        float64 // The quadratic coefficient.
+}
+
+func (c *LatencyCurve) String() string {
+	return fmt.Sprintf("LatencyCurve{q=%v,a=%v,b=%v,c=%v}", c.Quantile, c.A, c.B, c.C)
+}
+
+// LatencyAtConcurrency returns the expected latency at the curve's quantile given a number of
+// concurrent events, R_q(N).
+func (c *LatencyCurve) LatencyAtConcurrency(n float64) float64 {
+	return c.A + c.B*n + c.C*n*n
+}
+
+// FitLatencyQuantile returns a latency curve for the given quantile, fitted using linear least
+// squares to those observations which have a latency at that quantile.
+func FitLatencyQuantile(observations []LatencyQuantiles, q float64) (*LatencyCurve, error) {
+	var n, r []float64
+
+	for i := range observations {
+		if v, ok := observations[i].LatencyAtQuantile(q); ok {
+			n = append(n, observations[i].Concurrency)
+			r = append(r, v)
+		}
+	}
+
+	if len(n) < minMeasurements {
+		return nil, ErrInsufficientMeasurements
+	}
+
+	// Build the design matrix of [1, N, N²] for each measurement.
+	a := mat.NewDense(len(n), 3, nil)
+	for i, v := range n {
+		a.SetRow(i, []float64{1, v, v * v})
+	}
+
+	var x mat.VecDense
+	if err := x.SolveVec(a, mat.NewVecDense(len(r), r)); err != nil {
+		return nil, fmt.Errorf("unable to fit latency curve: %w", err)
+	}
+
+	return &LatencyCurve{Quantile: q, A: x.AtVec(0), B: x.AtVec(1), C: x.AtVec(2)}, nil
+}
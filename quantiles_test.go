@@ -0,0 +1,64 @@
+package usl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/codahale/gubbins/assert"
+)
+
+func TestLatencyQuantiles_LatencyAtQuantile(t *testing.T) {
+	t.Parallel()
+
+	l := LatencyQuantiles{Quantiles: []Quantile{{Quantile: 0.5, Latency: 0.1}, {Quantile: 0.99, Latency: 0.4}}}
+
+	r, ok := l.LatencyAtQuantile(0.99)
+	assert.Equal(t, "ok", true, ok)
+	assert.Equal(t, "p99", 0.4, r)
+
+	_, ok = l.LatencyAtQuantile(0.999)
+	assert.Equal(t, "ok", false, ok)
+}
+
+func TestFitLatencyQuantile(t *testing.T) {
+	t.Parallel()
+
+	want := &LatencyCurve{Quantile: 0.99, A: 0.01, B: 0.002, C: 0.0003}
+
+	ls := make([]LatencyQuantiles, 0, 11)
+	for n := 1.0; n <= 10; n++ {
+		ls = append(ls, LatencyQuantiles{
+			Concurrency: n,
+			Quantiles:   []Quantile{{Quantile: 0.99, Latency: want.LatencyAtConcurrency(n)}},
+		})
+	}
+
+	// Observations without the quantile are ignored.
+	ls = append(ls, LatencyQuantiles{Concurrency: 11, Quantiles: []Quantile{{Quantile: 0.5, Latency: 1}}})
+
+	got, err := FitLatencyQuantile(ls, 0.99)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "LatencyCurve", want, got, epsilon)
+	assert.Equal(t, "R(N=12)", want.LatencyAtConcurrency(12), got.LatencyAtConcurrency(12), epsilon)
+}
+
+func TestFitLatencyQuantile_InsufficientMeasurements(t *testing.T) {
+	t.Parallel()
+
+	ls := []LatencyQuantiles{{Concurrency: 1, Quantiles: []Quantile{{Quantile: 0.99, Latency: 1}}}}
+
+	if _, err := FitLatencyQuantile(ls, 0.99); !errors.Is(err, ErrInsufficientMeasurements) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLatencyCurve_String(t *testing.T) {
+	t.Parallel()
+
+	c := &LatencyCurve{Quantile: 0.99, A: 1, B: 2, C: 3}
+
+	assert.Equal(t, "String", "LatencyCurve{q=0.99,a=1,b=2,c=3}", c.String())
+}
@@ -0,0 +1,186 @@
+// Package recorder measures the concurrency, throughput, and latency of arbitrary operations.
+//
+// A Recorder tracks operations as they begin and end, and snapshots of it can be turned into
+// measurements of the operations performed between them. This allows anything from worker pools to
+// database calls to queue consumers to be modeled from their actual workloads:
+//
+//     r := recorder.New()
+//
+//     func query() {
+//         defer r.End(r.Begin())
+//         ...
+//     }
+//
+//     prev := r.Snapshot()
+//     for range time.Tick(10 * time.Second) {
+//         next := r.Snapshot()
+//         measurements = append(measurements, next.Since(prev))
+//         prev = next
+//     }
+package recorder
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codahale/usl"
+)
+
+// Recorder records the beginnings and endings of operations. Begin and End are lock-free and safe
+// for concurrent use, as is Snapshot.
+type Recorder struct {
+	// Begin and End update one of two sets of counters, selected by the phase in the high bit of
+	// entered. Each increments entered before updating the counters and exited for its phase after,
+	// so Snapshot can flip the phase and wait for only those updates already in progress.
+	entered  uint64
+	exited   [2]uint64
+	counters [2]counters
+
+	mu    sync.Mutex // Serializes snapshots.
+	total counters   // The sum of the counters as of the last snapshot.
+
+	epoch time.Time
+	now   func() time.Time
+}
+
+// counters are the cumulative state of the operations recorded by a recorder.
+type counters struct {
+	inFlight  int64  // The number of operations in flight.
+	starts    int64  // The sum of the start times of the operations in flight.
+	latency   int64  // The total latency of the completed operations.
+	completed uint64 // The number of completed operations.
+}
+
+// New returns a new Recorder.
+func New() *Recorder {
+	return NewWithClock(time.Now)
+}
+
+// NewWithClock returns a new Recorder which reads the current time from the given function (e.g. a
+// fake clock in tests).
+func NewWithClock(now func() time.Time) *Recorder {
+	return &Recorder{epoch: now(), now: now}
+}
+
+// Start is the time at which an operation began.
+type Start int64
+
+// Begin records the beginning of an operation and returns its start time, which must be passed to
+// End when the operation ends.
+func (r *Recorder) Begin() Start {
+	start := r.clock()
+
+	phase := r.enter()
+	c := &r.counters[phase]
+	atomic.AddInt64(&c.inFlight, 1)
+	atomic.AddInt64(&c.starts, start)
+	r.exit(phase)
+
+	return Start(start)
+}
+
+// End records the ending of an operation which began at the given time.
+func (r *Recorder) End(start Start) {
+	end := r.clock()
+
+	phase := r.enter()
+	c := &r.counters[phase]
+	atomic.AddInt64(&c.inFlight, -1)
+	atomic.AddInt64(&c.starts, -int64(start))
+	atomic.AddInt64(&c.latency, end-int64(start))
+	atomic.AddUint64(&c.completed, 1)
+	r.exit(phase)
+}
+
+// Snapshot returns a snapshot of the recorder's counters.
+//
+// Snapshot waits only for calls to Begin and End which are already updating the counters, so it
+// returns in bounded time no matter how many operations are being recorded.
+func (r *Recorder) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Flip the phase, resetting the count of updates entered, and wait for the updates in the
+	// previous phase to exit. Its counters are then stable until the next flip.
+	prev := atomic.SwapUint64(&r.entered, (atomic.LoadUint64(&r.entered)^phaseBit)&phaseBit)
+	phase := prev >> 63
+
+	for atomic.LoadUint64(&r.exited[phase]) != prev&^phaseBit {
+		runtime.Gosched()
+	}
+
+	atomic.StoreUint64(&r.exited[phase], 0)
+
+	c := &r.counters[phase]
+	r.total.inFlight += atomic.SwapInt64(&c.inFlight, 0)
+	r.total.starts += atomic.SwapInt64(&c.starts, 0)
+	r.total.latency += atomic.SwapInt64(&c.latency, 0)
+	r.total.completed += atomic.SwapUint64(&c.completed, 0)
+
+	// Every update in the total read the clock before the flip, so none are later than now.
+	now := r.now()
+	t := int64(now.Sub(r.epoch))
+
+	return Snapshot{
+		Time:      now,
+		InFlight:  uint64(r.total.inFlight),
+		Completed: r.total.completed,
+		Latency:   time.Duration(r.total.latency),
+		Busy:      time.Duration(r.total.latency + r.total.inFlight*t - r.total.starts),
+	}
+}
+
+// enter marks the beginning of an update to the counters and returns the phase whose counters
+// should be updated.
+func (r *Recorder) enter() uint64 {
+	return atomic.AddUint64(&r.entered, 1) >> 63
+}
+
+// exit marks the end of an update to the counters of the given phase.
+func (r *Recorder) exit(phase uint64) {
+	atomic.AddUint64(&r.exited[phase], 1)
+}
+
+// phaseBit is the bit of Recorder.entered which holds the current phase.
+const phaseBit = 1 << 63
+
+// clock returns the number of nanoseconds since the recorder was created.
+func (r *Recorder) clock() int64 {
+	return int64(r.now().Sub(r.epoch))
+}
+
+// Snapshot is the state of a recorder at a point in time. Its counters are cumulative, and may
+// overflow and wrap around; the difference between two snapshots is correct as long as the
+// difference itself does not overflow.
+type Snapshot struct {
+	Time      time.Time     // When the snapshot was taken.
+	InFlight  uint64        // The number of operations in flight.
+	Completed uint64        // The total number of completed operations.
+	Latency   time.Duration // The total latency of the completed operations.
+	Busy      time.Duration // The total time spent by all operations, including those in flight.
+}
+
+// Since returns a measurement of the operations performed between the given earlier snapshot and
+// this one. The concurrency is the mean number of operations in flight during the interval, the
+// throughput is the number of operations completed during the interval divided by its duration,
+// and the latency is the mean latency of those operations. If no operations were completed, the
+// latency is zero.
+func (s Snapshot) Since(prev Snapshot) usl.Measurement {
+	var m usl.Measurement
+
+	interval := s.Time.Sub(prev.Time).Seconds()
+	completed := float64(s.Completed - prev.Completed)
+
+	if interval > 0 {
+		m.Concurrency = (s.Busy - prev.Busy).Seconds() / interval
+		m.Throughput = completed / interval
+	}
+
+	if completed > 0 {
+		m.Latency = (s.Latency - prev.Latency).Seconds() / completed
+	}
+
+	return m
+}
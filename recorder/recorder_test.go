@@ -0,0 +1,149 @@
+package recorder
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+)
+
+func TestRecorder_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	r := NewWithClock(clock.now)
+	s0 := r.Snapshot()
+
+	a := r.Begin()
+
+	clock.advance(1 * time.Second)
+
+	b := r.Begin()
+
+	clock.advance(1 * time.Second)
+
+	s1 := r.Snapshot()
+
+	assert.Equal(t, "in flight", Snapshot{
+		Time:     time.Unix(2, 0),
+		InFlight: 2,
+		Busy:     3 * time.Second,
+	}, s1)
+
+	r.End(a)
+	clock.advance(1 * time.Second)
+	r.End(b)
+	clock.advance(1 * time.Second)
+
+	s2 := r.Snapshot()
+
+	assert.Equal(t, "completed", Snapshot{
+		Time:      time.Unix(4, 0),
+		Completed: 2,
+		Latency:   4 * time.Second,
+		Busy:      4 * time.Second,
+	}, s2)
+
+	assert.Equal(t, "whole interval",
+		usl.Measurement{Concurrency: 1, Throughput: 0.5, Latency: 2},
+		s2.Since(s0))
+	assert.Equal(t, "second half",
+		usl.Measurement{Concurrency: 0.5, Throughput: 1, Latency: 2},
+		s2.Since(s1))
+	assert.Equal(t, "empty interval", usl.Measurement{}, s2.Since(s2))
+}
+
+func TestRecorder_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	r := New()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				r.End(r.Begin())
+				_ = r.Snapshot()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	s := r.Snapshot()
+
+	assert.Equal(t, "completed", uint64(8000), s.Completed)
+	assert.Equal(t, "in flight", uint64(0), s.InFlight)
+	assert.Equal(t, "busy", s.Latency, s.Busy)
+}
+
+func TestRecorder_SnapshotUnderLoad(t *testing.T) {
+	t.Parallel()
+
+	const writers = 16
+
+	var (
+		r    = New()
+		wg   sync.WaitGroup
+		stop = make(chan struct{})
+	)
+
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					r.End(r.Begin())
+				}
+			}
+		}()
+	}
+
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	// Snapshots must not wait for the writers to pause, and must be consistent when they don't.
+	deadline := time.Now().Add(10 * time.Second)
+	prev := r.Snapshot()
+
+	for i := 0; i < 100; i++ {
+		if time.Now().After(deadline) {
+			t.Fatalf("took more than 10s to take %d snapshots", i)
+		}
+
+		s := r.Snapshot()
+
+		if s.InFlight > writers || s.Completed < prev.Completed || s.Busy < s.Latency {
+			t.Fatalf("inconsistent snapshot: %+v", s)
+		}
+
+		prev = s
+	}
+}
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
@@ -0,0 +1,194 @@
+package usl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Tracker maintains a time-windowed set of measurements of a production system and periodically
+// refits a model to them, reporting drift when the model's parameters move beyond thresholds. This
+// allows scalability regressions to be detected from telemetry, without scheduled load tests.
+//
+// A Tracker's exported fields must not be modified once it is in use. Its methods are safe for
+// concurrent use.
+type Tracker struct {
+	Window      time.Duration // How long measurements are kept.
+	BucketWidth float64       // If non-zero, measurements are merged into buckets of concurrency this wide.
+	Thresholds  Thresholds    // How far parameters may move before drift is reported.
+	OnDrift     func(Drift)   // If non-nil, called with each drift detected by Refit, without locks held.
+
+	mu           sync.Mutex
+	observations []observation
+	baseline     *Model
+}
+
+// Thresholds are the relative changes in a model's parameters beyond which they are considered to
+// have drifted (e.g. 0.1 for 10%). A zero threshold is ignored.
+type Thresholds struct {
+	Sigma         float64 // The maximum relative change in σ.
+	Kappa         float64 // The maximum relative change in κ.
+	MaxThroughput float64 // The maximum relative change in MaxThroughput.
+}
+
+// Drift is a change in one of a model's parameters beyond its threshold.
+type Drift struct {
+	At        time.Time // When the drift was detected.
+	Parameter string    // The parameter which drifted: "sigma", "kappa", or "max_throughput".
+	Baseline  float64   // The parameter's value in the baseline model.
+	Current   float64   // The parameter's value in the current model.
+	Change    float64   // The relative change from the baseline to the current value.
+}
+
+type observation struct {
+	at time.Time
+	m  Measurement
+}
+
+// Observe records a measurement taken at the given time.
+func (t *Tracker) Observe(at time.Time, m Measurement) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.observations = append(t.observations, observation{at: at, m: m})
+}
+
+// Refit discards measurements older than the window and fits a model to those which remain. If
+// the model's parameters aren't finite, ErrInvalidModel is returned. The first model fitted becomes
+// the baseline. If any of the model's parameters have drifted from the
+// baseline, the drifts are returned, passed to OnDrift, and the model becomes the new baseline.
+func (t *Tracker) Refit(now time.Time) (*Model, []Drift, error) {
+	m, drifts, err := t.refit(now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Call OnDrift after unlocking, so it can use the tracker.
+	if t.OnDrift != nil {
+		for _, d := range drifts {
+			t.OnDrift(d)
+		}
+	}
+
+	return m, drifts, nil
+}
+
+// refit does the work of Refit while holding the tracker's lock.
+func (t *Tracker) refit(now time.Time) (*Model, []Drift, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Discard old observations.
+	cutoff := now.Add(-t.Window)
+	kept := t.observations[:0]
+
+	for _, o := range t.observations {
+		if !o.at.Before(cutoff) {
+			kept = append(kept, o)
+		}
+	}
+
+	t.observations = kept
+
+	m, err := Build(t.measurements())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Don't compare against, or become, a baseline which can't be compared.
+	if !isFinite(m.Sigma) || !isFinite(m.Kappa) || !isFinite(m.Lambda) {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidModel, m)
+	}
+
+	if t.baseline == nil {
+		t.baseline = m
+
+		return m, nil, nil
+	}
+
+	drifts := t.drifts(now, t.baseline, m)
+	if len(drifts) > 0 {
+		t.baseline = m
+	}
+
+	return m, drifts, nil
+}
+
+// Run calls Refit at the given interval until the context is canceled. Refits which fail, such as
+// when there are too few measurements, are skipped.
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			_, _, _ = t.Refit(now)
+		}
+	}
+}
+
+// measurements returns the observed measurements, merged into buckets by concurrency if a bucket
+// width is set. Measurements with no concurrency are ignored.
+func (t *Tracker) measurements() []Measurement {
+	ms := make(Measurements, 0, len(t.observations))
+
+	for _, o := range t.observations {
+		if o.m.Concurrency > 0 {
+			ms = append(ms, o.m)
+		}
+	}
+
+	if t.BucketWidth == 0 {
+		return ms
+	}
+
+	// Place each bucket at the mean concurrency of its measurements, rather than at its center, so
+	// that low concurrencies aren't rounded down to zero.
+	sums := make(map[float64]float64)
+	counts := make(map[float64]float64)
+
+	for _, m := range ms {
+		bucket := math.Round(m.Concurrency / t.BucketWidth)
+		sums[bucket] += m.Concurrency
+		counts[bucket]++
+	}
+
+	for i := range ms {
+		bucket := math.Round(ms[i].Concurrency / t.BucketWidth)
+		ms[i].Concurrency = sums[bucket] / counts[bucket]
+	}
+
+	return ms.Merge(AggregateMean)
+}
+
+func (t *Tracker) drifts(now time.Time, baseline, current *Model) []Drift {
+	var drifts []Drift
+
+	for _, p := range []struct {
+		name              string
+		threshold         float64
+		baseline, current float64
+	}{
+		{"sigma", t.Thresholds.Sigma, baseline.Sigma, current.Sigma},
+		{"kappa", t.Thresholds.Kappa, baseline.Kappa, current.Kappa},
+		{"max_throughput", t.Thresholds.MaxThroughput, baseline.MaxThroughput(), current.MaxThroughput()},
+	} {
+		change := (p.current - p.baseline) / math.Abs(p.baseline)
+		if p.threshold > 0 && math.Abs(change) > p.threshold {
+			drifts = append(drifts, Drift{
+				At:        now,
+				Parameter: p.name,
+				Baseline:  p.baseline,
+				Current:   p.current,
+				Change:    change,
+			})
+		}
+	}
+
+	return drifts
+}
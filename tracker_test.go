@@ -0,0 +1,215 @@
+package usl
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	var drifted []Drift
+
+	tracker := &Tracker{
+		Window:      time.Hour,
+		BucketWidth: 4,
+		Thresholds:  Thresholds{Sigma: 0.5, Kappa: 0.1, MaxThroughput: 0.1},
+		OnDrift: func(d Drift) {
+			drifted = append(drifted, d)
+		},
+	}
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := &Model{Sigma: 0.02, Kappa: 0.0005, Lambda: 1000}
+	after := &Model{Sigma: 0.02, Kappa: 0.001, Lambda: 1000}
+
+	observe := func(m *Model, at time.Time) {
+		// Observe each level of concurrency twice, slightly off-center in its bucket.
+		for n := 4.0; n <= 40; n += 4 {
+			for _, d := range []float64{-1, 1} {
+				tracker.Observe(at, Measurement{
+					Concurrency: n + d,
+					Throughput:  m.ThroughputAtConcurrency(n),
+					Latency:     m.LatencyAtConcurrency(n),
+				})
+			}
+		}
+	}
+
+	if _, _, err := tracker.Refit(start); !errors.Is(err, ErrInsufficientMeasurements) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	observe(before, start)
+
+	m, drifts, err := tracker.Refit(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Baseline", before, m, epsilonLoose)
+	assert.Equal(t, "Drifts", []Drift(nil), drifts)
+
+	// Once the old measurements age out of the window, the new ones show a regression.
+	now := start.Add(90 * time.Minute)
+	observe(after, now)
+
+	m, drifts, err = tracker.Refit(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Current", after, m, epsilonLoose)
+	assert.Equal(t, "Drifts", []Drift{
+		{
+			At:        now,
+			Parameter: "kappa",
+			Baseline:  before.Kappa,
+			Current:   after.Kappa,
+			Change:    1,
+		},
+		{
+			At:        now,
+			Parameter: "max_throughput",
+			Baseline:  before.MaxThroughput(),
+			Current:   after.MaxThroughput(),
+			Change:    after.MaxThroughput()/before.MaxThroughput() - 1,
+		},
+	}, drifts, epsilonLoose)
+	assert.Equal(t, "OnDrift", drifts, drifted)
+
+	// The drifted model is the new baseline.
+	_, drifts, err = tracker.Refit(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Drifts", []Drift(nil), drifts)
+}
+
+func TestTracker_SubBucketConcurrency(t *testing.T) {
+	t.Parallel()
+
+	tracker := &Tracker{Window: time.Hour, BucketWidth: 4}
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := &Model{Sigma: 0.02, Kappa: 0.0005, Lambda: 1000}
+
+	// The lowest concurrencies are all less than half a bucket wide.
+	for _, n := range []float64{0.5, 1, 1.5, 3, 5, 9, 13, 17, 21, 25, 29, 33} {
+		tracker.Observe(now, Measurement{
+			Concurrency: n,
+			Throughput:  want.ThroughputAtConcurrency(n),
+			Latency:     want.LatencyAtConcurrency(n),
+		})
+	}
+
+	got, _, err := tracker.Refit(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Merging measurements of a curve is only approximate, but no bucket is placed at zero.
+	assert.Equal(t, "Model", want, got, cmpopts.EquateApprox(0.05, 0))
+}
+
+func TestTracker_NonFiniteModel(t *testing.T) {
+	t.Parallel()
+
+	tracker := &Tracker{Window: time.Hour}
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for n := 1.0; n <= 6; n++ {
+		tracker.Observe(now, ConcurrencyAndThroughput(uint64(n), math.Inf(1)))
+	}
+
+	if _, _, err := tracker.Refit(now); !errors.Is(err, ErrInvalidModel) {
+		t.Errorf("Refit() = %v, want %v", err, ErrInvalidModel)
+	}
+}
+
+func TestTracker_ReentrantOnDrift(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tracker *Tracker
+		refits  int
+	)
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker = &Tracker{
+		Window:     time.Hour,
+		Thresholds: Thresholds{Kappa: 0.1},
+		OnDrift: func(d Drift) {
+			tracker.Observe(d.At, ConcurrencyAndThroughput(1, 1000))
+
+			if _, _, err := tracker.Refit(d.At); err != nil {
+				t.Error(err)
+			}
+
+			refits++
+		},
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer close(done)
+
+		// Fit a baseline, then observe a regression once its measurements age out of the window.
+		for _, m := range []*Model{{Sigma: 0.02, Kappa: 0.0005, Lambda: 1000}, {Sigma: 0.02, Kappa: 0.001, Lambda: 1000}} {
+			now = now.Add(90 * time.Minute)
+
+			for n := 1.0; n <= 32; n *= 2 {
+				tracker.Observe(now, Measurement{
+					Concurrency: n,
+					Throughput:  m.ThroughputAtConcurrency(n),
+					Latency:     m.LatencyAtConcurrency(n),
+				})
+			}
+
+			if _, _, err := tracker.Refit(now); err != nil {
+				done <- err
+
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnDrift deadlocked")
+	}
+
+	assert.Equal(t, "refits", 1, refits)
+}
+
+func TestTracker_Run(t *testing.T) {
+	t.Parallel()
+
+	tracker := &Tracker{Window: time.Hour}
+
+	for _, m := range measurements {
+		tracker.Observe(time.Now(), m)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := tracker.Run(ctx, 10*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if tracker.baseline == nil {
+		t.Error("no model was fitted")
+	}
+}
@@ -0,0 +1,95 @@
+// Package usltest builds USL models of Go benchmarks.
+//
+// RunParallel runs a parallel benchmark body as a sub-benchmark at each of a series of parallelism
+// levels, converts each level's result into a measurement, and builds a model from them. The
+// model's parameters are reported as metrics of a final sub-benchmark, so they appear in the
+// output of go test -bench alongside each level's throughput and can be tracked with tools like
+// benchstat:
+//
+//     func BenchmarkCache(b *testing.B) {
+//         c := NewCache()
+//
+//         _, _ = usltest.RunParallel(b, usltest.DefaultParallelism, func(pb *testing.PB) {
+//             for pb.Next() {
+//                 c.Get("key")
+//             }
+//         })
+//     }
+package usltest
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/codahale/usl"
+)
+
+// DefaultParallelism are the parallelism levels used by most benchmarks. With SetParallelism, each
+// level is multiplied by GOMAXPROCS to give the number of goroutines.
+//
+//nolint:gochecknoglobals // effectively constant
+var DefaultParallelism = []int{1, 2, 4, 8, 16, 32, 64}
+
+// RunParallel runs the body as a parallel sub-benchmark of b at each of the given parallelism
+// levels and returns a model of the results, reporting the model's parameters as metrics of a
+// final sub-benchmark named "usl".
+//
+// Each level's concurrency is its number of goroutines, and its throughput is the number of
+// operations per second. Levels which are excluded by the -bench flag, and levels which fail, are
+// left out of the model. If too few levels were run to build a model, the error is returned and no
+// model is reported.
+func RunParallel(b *testing.B, parallelism []int, body func(*testing.PB)) (*usl.Model, error) {
+	b.Helper()
+
+	measurements := make([]usl.Measurement, 0, len(parallelism))
+
+	for _, p := range parallelism {
+		p := p
+
+		var (
+			m   usl.Measurement
+			ran bool
+		)
+
+		// Sub-benchmarks are run repeatedly with increasing b.N, so keep the last result. b.Run
+		// reports success for sub-benchmarks excluded by the -bench flag without running them, so
+		// only use the result if there is one.
+		if b.Run(fmt.Sprintf("parallelism=%d", p), func(b *testing.B) {
+			b.SetParallelism(p)
+
+			start := time.Now()
+			b.RunParallel(body)
+			m = measurement(p*runtime.GOMAXPROCS(0), b.N, time.Since(start))
+
+			b.ReportMetric(m.Throughput, "ops/s")
+
+			ran = true
+		}) && ran {
+			measurements = append(measurements, m)
+		}
+	}
+
+	model, err := usl.Build(measurements)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Run("usl", func(b *testing.B) {
+		b.ReportMetric(0, "ns/op")
+		b.ReportMetric(model.Sigma, "sigma")
+		b.ReportMetric(model.Kappa, "kappa")
+		b.ReportMetric(model.Lambda, "lambda")
+		b.ReportMetric(model.MaxConcurrency(), "peak-concurrency")
+		b.ReportMetric(model.MaxThroughput(), "peak-ops/s")
+	})
+
+	return model, nil
+}
+
+// measurement returns a measurement of n goroutines performing the given number of operations over
+// the elapsed time.
+func measurement(n, ops int, elapsed time.Duration) usl.Measurement {
+	return usl.ConcurrencyAndThroughput(uint64(n), float64(ops)/elapsed.Seconds())
+}
@@ -0,0 +1,100 @@
+package usltest
+
+import (
+	"errors"
+	"flag"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codahale/gubbins/assert"
+	"github.com/codahale/usl"
+)
+
+func TestMeasurement(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "measurement",
+		usl.Measurement{Concurrency: 8, Throughput: 2000, Latency: 0.004},
+		measurement(8, 1000, 500*time.Millisecond))
+}
+
+//nolint:paralleltest // sets the -test.benchtime flag
+func TestRunParallel(t *testing.T) {
+	setBenchTime(t, "100x")
+
+	var (
+		model *usl.Model
+		err   error
+	)
+
+	testing.Benchmark(func(b *testing.B) {
+		model, err = RunParallel(b, []int{1, 2, 4, 8, 16, 32}, func(pb *testing.PB) {
+			for pb.Next() {
+				time.Sleep(time.Microsecond)
+			}
+		})
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if model == nil || !(model.Lambda > 0) || math.IsNaN(model.Sigma) || math.IsNaN(model.Kappa) {
+		t.Errorf("RunParallel() = %v, want a model with a positive throughput", model)
+	}
+}
+
+//nolint:paralleltest // sets the -test.benchtime flag
+func TestRunParallel_TooFewLevels(t *testing.T) {
+	setBenchTime(t, "100x")
+
+	var err error
+
+	testing.Benchmark(func(b *testing.B) {
+		_, err = RunParallel(b, []int{1, 2}, func(pb *testing.PB) {
+			for pb.Next() {
+			}
+		})
+	})
+
+	if !errors.Is(err, usl.ErrInsufficientMeasurements) {
+		t.Errorf("RunParallel() = %v, want %v", err, usl.ErrInsufficientMeasurements)
+	}
+}
+
+// setBenchTime sets the -test.benchtime flag for the rest of the test, so that benchmarks run by
+// testing.Benchmark finish quickly.
+func setBenchTime(t *testing.T, d string) {
+	t.Helper()
+
+	f := flag.Lookup("test.benchtime")
+	prev := f.Value.String()
+
+	if err := f.Value.Set(d); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = f.Value.Set(prev)
+	})
+}
+
+func BenchmarkRunParallel(b *testing.B) {
+	var (
+		mu sync.Mutex
+		n  int
+	)
+
+	_, err := RunParallel(b, DefaultParallelism, func(pb *testing.PB) {
+		for pb.Next() {
+			mu.Lock()
+			n++
+			mu.Unlock()
+		}
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+}